@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// BuildStats describes how a single variant job was built
+type BuildStats struct {
+	JobID string
+	// the whole job, from checkout to the end of the build
+	JobDuration time.Duration
+	// the variant only: building its image and running it
+	VariantDuration time.Duration
+	// the image build phase only: from the start of the job to the creation of the last layer it built.
+	// Zero when the whole image came from the cache
+	ImageBuildDuration time.Duration
+	// the variant build image, and its layers ids, from the top one down to the base image
+	Image  string
+	Layers []string
+	// the number of layers built by this job, the others came from the cache
+	BuiltLayers int
+}
+
+func (s *BuildStats) ImageID() string {
+	if len(s.Layers) == 0 {
+		return ""
+	}
+	return s.Layers[0]
+}
+
+// SharedLayers returns the number of layers the two build images have in common
+func (s *BuildStats) SharedLayers(other *BuildStats) int {
+	ids := map[string]bool{}
+	for _, l := range other.Layers {
+		ids[l] = true
+	}
+
+	shared := 0
+	for _, l := range s.Layers {
+		if ids[l] {
+			shared++
+		}
+	}
+	return shared
+}
+
+func (s *BuildStats) String() string {
+	return fmt.Sprintf("job %s: job %v, variant %v, image build %v, image %s (%s, %d layers, %d built)",
+		s.JobID, s.JobDuration, s.VariantDuration, s.ImageBuildDuration, s.Image, s.ImageID(), len(s.Layers), s.BuiltLayers)
+}
+
+// BuildStats collects the timings and build image of a completed job with a single variant
+func (b *Bzk) BuildStats(jobID string) *BuildStats {
+	job, err := b.Api.Job.Get(jobID)
+	if err != nil {
+		b.t.Fatalf("Error while getting the job %s: %v", jobID, err)
+	}
+
+	variants, err := b.Api.Job.Variants(jobID)
+	if err != nil {
+		b.t.Fatalf("Error while listing the job %s variants: %v", jobID, err)
+	}
+	if len(variants) != 1 {
+		b.t.Fatalf("Job %s should have exactly one variant, got %d", jobID, len(variants))
+	}
+	variant := variants[0]
+
+	stats := &BuildStats{
+		JobID:           jobID,
+		JobDuration:     job.Completed.Sub(job.Started),
+		VariantDuration: variant.Completed.Sub(variant.Started),
+		Image:           variant.BuildImage,
+	}
+
+	// the layers created since the job started were built by it, the top one last
+	for i, image := range b.imageLayers(variant.BuildImage) {
+		stats.Layers = append(stats.Layers, image.ID)
+		if !image.Created.Before(job.Started) {
+			stats.BuiltLayers++
+			if i == 0 {
+				stats.ImageBuildDuration = image.Created.Sub(job.Started)
+			}
+		}
+	}
+
+	b.t.Logf("Build stats of %v", stats)
+	return stats
+}
+
+// imageLayers returns the image and all its parents, from the top one down to the base image
+func (b *Bzk) imageLayers(name string) []*docker.Image {
+	var layers []*docker.Image
+	for len(name) > 0 {
+		image, err := b.docker.InspectImage(name)
+		if err != nil {
+			b.t.Fatalf("Error while inspecting the image %s: %v", name, err)
+		}
+		layers = append(layers, image)
+		name = image.Parent
+	}
+	return layers
+}
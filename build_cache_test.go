@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestBuildImageCache(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("cache-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	first := runCachedJob(t, bzk, proj.ID)
+
+	// the same commit again: everything should come from the cache
+	second := runCachedJob(t, bzk, proj.ID)
+	require.Equal(t, first.ImageID(), second.ImageID(), "building the same commit twice should reuse the build image")
+	require.True(t, first.BuiltLayers > 0, "the first build should build its image")
+	require.Equal(t, 0, second.BuiltLayers, "a cached build should not build any layer")
+	require.Equal(t, time.Duration(0), second.ImageBuildDuration, "a cached build should skip the image build phase")
+	require.True(t, second.VariantDuration <= first.VariantDuration+5*time.Second,
+		"a cached build should not be slower: %v the first time, %v the second", first.VariantDuration, second.VariantDuration)
+
+	// only the sources change: the layers derived from .bazooka.yml are still cached
+	repo.ImportFile("data/params-project/main.go", "main.go")
+	repo.GitAddAll()
+	repo.GitCommit("Change the sources")
+
+	sourceChange := runCachedJob(t, bzk, proj.ID)
+	require.NotEqual(t, first.ImageID(), sourceChange.ImageID(), "changed sources should produce a new build image")
+	require.True(t, sourceChange.BuiltLayers < first.BuiltLayers,
+		"a source change should build fewer layers than the first build: %d against %d", sourceChange.BuiltLayers, first.BuiltLayers)
+
+	// .bazooka.yml changes: the layers derived from it are rebuilt
+	repo.WriteConfig(&BzkConfig{
+		Language:     "golang",
+		Go:           []string{"1.4"},
+		BeforeScript: []string{"echo config change"},
+	})
+	repo.GitAddAll()
+	repo.GitCommit("Change the config")
+
+	configChange := runCachedJob(t, bzk, proj.ID)
+	require.NotEqual(t, sourceChange.ImageID(), configChange.ImageID(), "a changed config should produce a new build image")
+
+	t.Logf("Shared layers with the first build: same commit %d, source change %d, config change %d (out of %d)",
+		second.SharedLayers(first), sourceChange.SharedLayers(first), configChange.SharedLayers(first), len(first.Layers))
+	require.True(t, sourceChange.SharedLayers(first) > 1, "a source change should reuse more than the base image")
+	require.True(t, sourceChange.SharedLayers(first) >= configChange.SharedLayers(first),
+		"a source change should reuse at least as many layers as a config change")
+}
+
+func runCachedJob(t *testing.T, bzk *Bzk, projectID string) *BuildStats {
+	job, err := bzk.Api.Project.StartJob(projectID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 120*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	return bzk.BuildStats(job.ID)
+}
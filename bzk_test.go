@@ -10,6 +10,7 @@ import (
 )
 
 func TestSimpleGoProject(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
@@ -41,6 +42,7 @@ func TestSimpleGoProject(t *testing.T) {
 }
 
 func TestSimpleJavaProject(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
@@ -72,6 +74,7 @@ func TestSimpleJavaProject(t *testing.T) {
 }
 
 func TestSimplePythonProject(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
@@ -103,6 +106,7 @@ func TestSimplePythonProject(t *testing.T) {
 }
 
 func TestSimpleNodejsProject(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
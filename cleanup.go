@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dockercmd "github.com/bywan/go-dockercommand"
+)
+
+const (
+	// any image with rm and GNU find will do, this one is already needed by the tests
+	cleanupImage = "bazooka/e2e-git"
+)
+
+// removeAll deletes path and everything it contains, then checks it's really gone.
+// Build containers write files as root in the bazooka home and in the repositories, which the user running the tests
+// may not be allowed to delete: the deletion is then done by a short-lived container instead
+func removeAll(t *testing.T, dockerClient *dockercmd.Docker, path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		t.Logf("Cannot delete %s as uid %d, using a helper container instead: %v", path, os.Getuid(), err)
+		if err := removeAllInContainer(dockerClient, path); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		return fmt.Errorf("%s is still there after its deletion (%v)", path, err)
+	}
+	return nil
+}
+
+func removeAllInContainer(dockerClient *dockercmd.Docker, path string) error {
+	parent, base := filepath.Split(filepath.Clean(path))
+
+	container, err := dockerClient.Run(&dockercmd.RunOptions{
+		Image: cleanupImage,
+		VolumeBinds: []string{
+			fmt.Sprintf("%s:/cleanup", parent),
+		},
+		Cmd: []string{"rm", "-rf", filepath.Join("/cleanup", base)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start the cleanup container: %v", err)
+	}
+	defer container.Remove(&dockercmd.RemoveOptions{
+		Force:         true,
+		RemoveVolumes: true,
+	})
+
+	exitCode, err := container.Wait()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the exit code of the cleanup container: %v", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("the cleanup container failed with exit code %d", exitCode)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// the project config keys pointing the server to the code host API
+	codeHostAPIConfigKey   = "github.api_url"
+	codeHostTokenConfigKey = "github.token"
+	codeHostRepoConfigKey  = "github.repository"
+
+	fakeCodeHostToken = "bzk-e2e-token"
+	fakeCodeHostRepo  = "bazooka-ci/e2e"
+)
+
+// CommitStatus is a status posted to the code host for a commit
+type CommitStatus struct {
+	SHA         string    `json:"-"`
+	State       string    `json:"state"`
+	TargetURL   string    `json:"target_url"`
+	Description string    `json:"description"`
+	Context     string    `json:"context"`
+	Received    time.Time `json:"-"`
+}
+
+// Comment is a comment posted to the code host, either on a commit or on an issue/pull request
+type Comment struct {
+	SHA   string `json:"-"`
+	Issue string `json:"-"`
+	Body  string `json:"body"`
+	Path  string `json:"path,omitempty"`
+	Line  int    `json:"line,omitempty"`
+}
+
+// FakeCodeHost is an in-process stand-in for the github statuses and comments API endpoints.
+// It records everything posted to it
+type FakeCodeHost struct {
+	URL string
+
+	t        *testing.T
+	listener net.Listener
+
+	mu       sync.Mutex
+	statuses []CommitStatus
+	comments []Comment
+}
+
+func (b *Bzk) NewFakeCodeHost() *FakeCodeHost {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		b.t.Fatalf("Failed to listen for the fake code host: %v", err)
+	}
+
+	h := &FakeCodeHost{
+		URL:      fmt.Sprintf("http://%s:%d", serverHost, listener.Addr().(*net.TCPAddr).Port),
+		t:        b.t,
+		listener: listener,
+	}
+	go http.Serve(listener, h)
+	b.t.Logf("Started a fake code host at %s", h.URL)
+
+	b.codeHosts = append(b.codeHosts, h)
+	return h
+}
+
+// Configure points the project to this code host
+func (h *FakeCodeHost) Configure(b *Bzk, projectID string) {
+	for k, v := range map[string]string{
+		codeHostAPIConfigKey:   h.URL,
+		codeHostTokenConfigKey: fakeCodeHostToken,
+		codeHostRepoConfigKey:  fakeCodeHostRepo,
+	} {
+		if err := b.Api.Project.Config.SetKey(projectID, k, v); err != nil {
+			h.t.Fatalf("Error while setting the project config key %s: %v", k, err)
+		}
+	}
+}
+
+func (h *FakeCodeHost) teardown() {
+	h.t.Logf("Stopping the fake code host at %s", h.URL)
+	if err := h.listener.Close(); err != nil {
+		h.t.Errorf("Error while stopping the fake code host: %v", err)
+	}
+}
+
+// Statuses returns the statuses posted for the given commit, in the order they were received
+func (h *FakeCodeHost) Statuses(sha string) []CommitStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var res []CommitStatus
+	for _, s := range h.statuses {
+		if s.SHA == sha {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// States returns the states of the statuses posted for the given commit, in the order they were received
+func (h *FakeCodeHost) States(sha string) []string {
+	var res []string
+	for _, s := range h.Statuses(sha) {
+		res = append(res, s.State)
+	}
+	return res
+}
+
+func (h *FakeCodeHost) Comments() []Comment {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Comment{}, h.comments...)
+}
+
+// WaitForFinalStatus waits for a status other than pending to be posted for the given commit,
+// and returns all the statuses received for it
+func (h *FakeCodeHost) WaitForFinalStatus(sha string, timeoutAfter time.Duration) []CommitStatus {
+	giveUp := time.After(timeoutAfter)
+	for {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			statuses := h.Statuses(sha)
+			if len(statuses) > 0 && statuses[len(statuses)-1].State != "pending" {
+				return statuses
+			}
+		case <-giveUp:
+			h.t.Fatalf("Gave up waiting for the final status of commit %s after %v, got %v", sha, timeoutAfter, h.States(sha))
+		}
+	}
+}
+
+// ServeHTTP handles:
+//   - POST and GET /repos/{owner}/{repo}/statuses/{sha}
+//   - POST /repos/{owner}/{repo}/commits/{sha}/comments
+//   - POST /repos/{owner}/{repo}/issues/{number}/comments
+func (h *FakeCodeHost) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.t.Logf("[fake-code-host] %s %s", r.Method, r.URL.Path)
+
+	if r.Header.Get("Authorization") != "token "+fakeCodeHostToken {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 5 || parts[0] != "repos" || strings.Join(parts[1:3], "/") != fakeCodeHostRepo {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 5 && parts[3] == "statuses" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, h.Statuses(parts[4]))
+
+	case len(parts) == 5 && parts[3] == "statuses" && r.Method == "POST":
+		var s CommitStatus
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.SHA, s.Received = parts[4], time.Now()
+
+		h.mu.Lock()
+		h.statuses = append(h.statuses, s)
+		h.mu.Unlock()
+		writeJSON(w, http.StatusCreated, s)
+
+	case len(parts) == 6 && (parts[3] == "commits" || parts[3] == "issues") && parts[5] == "comments" && r.Method == "POST":
+		var c Comment
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if parts[3] == "commits" {
+			c.SHA = parts[4]
+		} else {
+			c.Issue = parts[4]
+		}
+
+		h.mu.Lock()
+		h.comments = append(h.comments, c)
+		h.mu.Unlock()
+		writeJSON(w, http.StatusCreated, c)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
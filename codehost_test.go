@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestCommitStatusOnSuccess(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	codeHost := bzk.NewFakeCodeHost()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("status-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	codeHost.Configure(bzk, proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	sha := repo.GitRevision("master")
+	codeHost.WaitForFinalStatus(sha, 10*time.Second)
+	require.Equal(t, []string{"pending", "success"}, codeHost.States(sha), "unexpected commit statuses sequence")
+}
+
+func TestCommitStatusOnFailure(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	codeHost := bzk.NewFakeCodeHost()
+
+	repo := bzk.NewRepository()
+	repo.ImportFixture("data/go-project", "data/overlays/failing-test")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("status-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	codeHost.Configure(bzk, proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_FAILED, jobStatus)
+
+	sha := repo.GitRevision("master")
+	codeHost.WaitForFinalStatus(sha, 10*time.Second)
+	require.Equal(t, []string{"pending", "failure"}, codeHost.States(sha), "unexpected commit statuses sequence")
+}
@@ -0,0 +1,68 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestCommitTriggersBuild(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+
+	proj, err := bzk.Api.Project.Create("trigger-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	repo.NotifyCommitsTo(bzk.GithubHookURL(proj.ID))
+
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	requirePushedJob(t, bzk, proj.ID, repo.PushEvent("master"))
+
+	repo.ImportFile("data/params-project/main.go", "main.go")
+	repo.GitAddAll()
+	repo.GitCommit("Second commit")
+
+	event := repo.PushEvent("master")
+	job := bzk.WaitForProjectJob(proj.ID, 2, 20*time.Second)
+	require.Equal(t, event.SHA, job.SCMMetadata.CommitID, "the second commit should have triggered its own job")
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
+
+func TestPushTriggersBuild(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("push-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	// only configured now, so that the commit above doesn't trigger anything
+	repo.NotifyCommitsTo(bzk.GithubHookURL(proj.ID))
+
+	repo.GitPush("pushed")
+
+	job := bzk.WaitForProjectJob(proj.ID, 1, 20*time.Second)
+	require.Equal(t, repo.GitRevision("pushed"), job.SCMMetadata.CommitID, "the push should have triggered a job")
+	require.Contains(t, job.SCMMetadata.Reference, "pushed", "the job should build the pushed branch")
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
@@ -0,0 +1,95 @@
+package e2e
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"text/tabwriter"
+)
+
+const (
+	compatPass = "pass"
+	compatFail = "fail"
+	compatSkip = "skip"
+)
+
+var (
+	// the bazooka/server tag used by NewBazooka
+	serverTag = "latest"
+
+	compat = &compatMatrix{results: map[string]map[string]string{}}
+)
+
+// compatMatrix records the outcome of every scenario against every server tag
+type compatMatrix struct {
+	mu      sync.Mutex
+	tags    []string
+	results map[string]map[string]string
+}
+
+func (c *compatMatrix) addTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tags = append(c.tags, tag)
+}
+
+func (c *compatMatrix) record(scenario, tag, result string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.results[scenario]; !found {
+		c.results[scenario] = map[string]string{}
+	}
+	c.results[scenario][tag] = result
+}
+
+// write prints the matrix as a table, one row per scenario and one column per tag.
+// Scenarios without a result for a tag are reported as skipped
+func (c *compatMatrix) write(out io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var scenarios []string
+	for s := range c.results {
+		scenarios = append(scenarios, s)
+	}
+	sort.Strings(scenarios)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprint(w, "scenario")
+	for _, tag := range c.tags {
+		fmt.Fprintf(w, "\t%s", tag)
+	}
+	fmt.Fprintln(w)
+
+	for _, s := range scenarios {
+		fmt.Fprint(w, s)
+		for _, tag := range c.tags {
+			result, found := c.results[s][tag]
+			if !found {
+				result = compatSkip
+			}
+			fmt.Fprintf(w, "\t%s", result)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+// recordCompat records the outcome of the test against the server tag being tested once it's over,
+// whether it passes, fails or is skipped, before or after starting a bazooka instance.
+// Every test calls it first thing, except TestUpgrade, which picks its own tags
+func recordCompat(t *testing.T) {
+	tag := serverTag
+	t.Cleanup(func() {
+		result := compatPass
+		switch {
+		case t.Skipped():
+			result = compatSkip
+		case t.Failed():
+			result = compatFail
+		}
+		compat.record(t.Name(), tag, result)
+	})
+}
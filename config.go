@@ -0,0 +1,78 @@
+package e2e
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	configFile = ".bazooka.yml"
+)
+
+// BzkConfig models the content of a .bazooka.yml file
+type BzkConfig struct {
+	Language string `yaml:"language,omitempty"`
+	Image    string `yaml:"image,omitempty"`
+
+	Go     []string `yaml:"go,omitempty"`
+	JDK    []string `yaml:"jdk,omitempty"`
+	Python []string `yaml:"python,omitempty"`
+	NodeJS []string `yaml:"node_js,omitempty"`
+
+	Env    []Env   `yaml:"env,omitempty"`
+	Matrix *Matrix `yaml:"matrix,omitempty"`
+
+	Services []string `yaml:"services,omitempty"`
+
+	BeforeInstall []string `yaml:"before_install,omitempty"`
+	Install       []string `yaml:"install,omitempty"`
+	BeforeScript  []string `yaml:"before_script,omitempty"`
+	Script        []string `yaml:"script,omitempty"`
+	AfterScript   []string `yaml:"after_script,omitempty"`
+	AfterSuccess  []string `yaml:"after_success,omitempty"`
+	AfterFailure  []string `yaml:"after_failure,omitempty"`
+}
+
+// Env is an entry of the env list: either a plain NAME=value variable or an encrypted one
+type Env struct {
+	Name   string
+	Value  string
+	Secure string
+}
+
+func EnvVar(name, value string) Env {
+	return Env{Name: name, Value: value}
+}
+
+// SecureEnv creates an env entry from data encrypted with the project's key (see Api.Project.EncryptData)
+func SecureEnv(encrypted string) Env {
+	return Env{Secure: encrypted}
+}
+
+func (e Env) MarshalYAML() (interface{}, error) {
+	if len(e.Secure) > 0 {
+		return map[string]string{"secure": e.Secure}, nil
+	}
+	return fmt.Sprintf("%s=%s", e.Name, e.Value), nil
+}
+
+// Matrix lists the variants to exclude from the generated matrix.
+// Each entry maps a matrix dimension (go, jdk, env, ...) to the value to exclude
+type Matrix struct {
+	Exclude []map[string]interface{} `yaml:"exclude,omitempty"`
+}
+
+// WriteConfig serializes the config into the repository's .bazooka.yml, replacing any existing one
+func (r *Repository) WriteConfig(cfg *BzkConfig) {
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		r.t.Fatalf("Error while serializing the bazooka config: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(r.location, configFile), b, 0644); err != nil {
+		r.t.Fatalf("Error while writing the bazooka config to the repository %d: %v", r.index, err)
+	}
+	r.t.Logf("Wrote the bazooka config of repository %d:\n%s", r.index, b)
+}
@@ -0,0 +1,47 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestWrittenConfig(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+
+	proj, err := bzk.Api.Project.Create("config-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	encryptedData, err := bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+	require.NoError(t, err, "error while encrypting data")
+
+	repo.ImportDir("data/go-project")
+	repo.ImportFile("data/overlays/check-secure-env/main_test.go", "main_test.go")
+	repo.WriteConfig(&BzkConfig{
+		Language: "golang",
+		Go:       []string{"1.4"},
+		Env: []Env{
+			EnvVar("PARAM", "42"),
+			SecureEnv(encryptedData),
+		},
+		Script: []string{"go test -v ./..."},
+	})
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
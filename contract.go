@@ -0,0 +1,202 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	contractDir = "data/contract"
+)
+
+var (
+	// when set, the golden files are rewritten with the responses of the server instead of being compared to them
+	updateGolden bool
+
+	contractDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`)
+	contractID   = regexp.MustCompile(`\b[0-9a-f]{24}\b`)
+	contractSha  = regexp.MustCompile(`\b[0-9a-f]{40}\b`)
+)
+
+// Contract records the wire format of the bazooka API responses, and normalizes them so that they can be compared
+// across runs: the values differing from one run to the other (ids, dates, shas, ...) are replaced by placeholders
+type Contract struct {
+	// known volatile values, replaced by their placeholder wherever they appear, paths included
+	placeholders map[string]string
+	// keys whose values aren't deterministic (log messages, cipher texts, ...), only their type is kept
+	masked map[string]bool
+}
+
+// ContractCall is a normalized API exchange, as stored in the golden files
+type ContractCall struct {
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type"`
+	Body        interface{} `json:"body"`
+}
+
+// NewContract creates a contract masking the values of the given keys, wherever they appear in the responses
+func NewContract(maskedKeys ...string) *Contract {
+	c := &Contract{
+		placeholders: map[string]string{},
+		masked:       map[string]bool{},
+	}
+	for _, k := range maskedKeys {
+		c.masked[k] = true
+	}
+	return c
+}
+
+// Placeholder makes the contract replace every occurrence of value by <name>
+func (c *Contract) Placeholder(name, value string) {
+	if len(value) > 0 {
+		c.placeholders[value] = fmt.Sprintf("<%s>", name)
+	}
+}
+
+// Normalize returns the normalized version of an API exchange
+func (c *Contract) Normalize(method, path string, res *APIResponse) *ContractCall {
+	call := &ContractCall{
+		Method: method,
+		Path:   c.normalizeString(path),
+		Status: res.Status,
+		// the charset and other parameters are not part of the contract
+		ContentType: strings.TrimSpace(strings.Split(res.Header.Get("Content-Type"), ";")[0]),
+	}
+
+	if len(res.Body) == 0 {
+		return call
+	}
+	var body interface{}
+	if err := json.Unmarshal(res.Body, &body); err != nil {
+		call.Body = "<not json>"
+		return call
+	}
+	call.Body = c.normalizeValue(body)
+	return call
+}
+
+func (c *Contract) normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		res := map[string]interface{}{}
+		for k, e := range v {
+			if c.masked[k] {
+				res[k] = fmt.Sprintf("<%s>", jsonType(e))
+				continue
+			}
+			res[k] = c.normalizeValue(e)
+		}
+		return res
+	case []interface{}:
+		// the number of items (log lines, variants, ...) is not part of the contract, their shape is
+		if len(v) == 0 {
+			return v
+		}
+		return []interface{}{c.normalizeValue(v[0])}
+	case string:
+		return c.normalizeString(v)
+	default:
+		return v
+	}
+}
+
+func (c *Contract) normalizeString(s string) string {
+	// longest values first, so that a value containing another one is replaced as a whole
+	var values []string
+	for value := range c.placeholders {
+		values = append(values, value)
+	}
+	sort.Sort(sort.Reverse(byLength(values)))
+	for _, value := range values {
+		s = strings.Replace(s, value, c.placeholders[value], -1)
+	}
+
+	if contractDate.MatchString(s) {
+		return "<date>"
+	}
+	s = contractSha.ReplaceAllString(s, "<sha>")
+	return contractID.ReplaceAllString(s, "<id>")
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+type byLength []string
+
+func (s byLength) Len() int           { return len(s) }
+func (s byLength) Less(i, j int) bool { return len(s[i]) < len(s[j]) }
+func (s byLength) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// RequireContract compares the normalized response to a request with the named golden file.
+// The placeholders for the values the response introduces (like the id of a new project) should be set beforehand
+func (b *Bzk) RequireContract(c *Contract, name, method, path string, res *APIResponse) {
+	actual, err := encodeContract(c.Normalize(method, path, res))
+	if err != nil {
+		b.t.Fatalf("Failed to encode the response of %s %s: %v", method, path, err)
+	}
+
+	if updateGolden {
+		if err := writeGolden(name, actual); err != nil {
+			b.t.Fatalf("Failed to write the golden file %s: %v", goldenFile(name), err)
+		}
+		b.t.Logf("Updated the golden file %s", goldenFile(name))
+		return
+	}
+
+	expected, err := readGolden(name)
+	if err != nil {
+		b.t.Fatalf("Failed to read the golden file %s, run the tests with -bzk.update-golden to create it: %v", goldenFile(name), err)
+	}
+	if string(expected) != string(actual) {
+		b.t.Errorf("The response of %s %s doesn't match the golden file %s\nexpected:\n%s\nactual:\n%s", method, path, goldenFile(name), expected, actual)
+	}
+}
+
+// goldenFile returns the location of the golden file of the named call
+func goldenFile(name string) string {
+	return filepath.Join(contractDir, name+".json")
+}
+
+// encodeContract returns the canonical encoding of the call, as stored in the golden files
+func encodeContract(call *ContractCall) ([]byte, error) {
+	payload, err := json.MarshalIndent(call, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(payload, '\n'), nil
+}
+
+func readGolden(name string) ([]byte, error) {
+	return ioutil.ReadFile(goldenFile(name))
+}
+
+func writeGolden(name string, payload []byte) error {
+	if err := os.MkdirAll(contractDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(goldenFile(name), payload, 0644)
+}
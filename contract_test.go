@@ -0,0 +1,99 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestAPIContract checks the wire format of every endpoint used by the client against the golden files in data/contract.
+// Run it with -bzk.update-golden to regenerate them after an intended change of the API
+func TestAPIContract(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	// log lines depend on the docker daemon, the go toolchain, ...
+	contract := NewContract("msg", "message")
+	contract.Placeholder("clone-url", repo.CloneURL())
+	contract.Placeholder("commit", repo.GitRevision("master"))
+
+	call := func(name, method, path string, body interface{}) *APIResponse {
+		res := bzk.RawRequest(method, path, body)
+		bzk.RequireContract(contract, name, method, path, res)
+		return res
+	}
+
+	// projects
+	res := bzk.RawRequest("POST", "/project", map[string]string{
+		"name":     "contract-proj",
+		"scm_type": "git",
+		"scm_uri":  repo.CloneURL(),
+	})
+	var proj lib.Project
+	require.NoError(t, res.Decode(&proj), "invalid project creation response: %s", res.Body)
+	contract.Placeholder("project-id", proj.ID)
+	bzk.RequireContract(contract, "project-create", "POST", "/project", res)
+
+	call("project-list", "GET", "/project", nil)
+	call("project-get", "GET", fmt.Sprintf("/project/%s", proj.ID), nil)
+	call("project-get-unknown", "GET", "/project/000000000000000000000000", nil)
+
+	// config
+	configPath := fmt.Sprintf("/project/%s/config/%s", proj.ID, projectConfigKey)
+	call("config-set", "PUT", configPath, map[string]string{"value": projectConfigValue})
+	call("config-get", "GET", fmt.Sprintf("/project/%s/config", proj.ID), nil)
+	call("config-unset", "DELETE", configPath, nil)
+
+	// encryption
+	cryptoPath := fmt.Sprintf("/project/%s/crypto", proj.ID)
+	res = bzk.RawRequest("POST", cryptoPath, map[string]string{"value": sensitiveData})
+	// the cipher text changes with every call
+	var cipher string
+	if err := res.Decode(&cipher); err == nil {
+		contract.Placeholder("cipher", cipher)
+	}
+	bzk.RequireContract(contract, "encrypt", "POST", cryptoPath, res)
+
+	// jobs
+	jobPath := fmt.Sprintf("/project/%s/job", proj.ID)
+	res = bzk.RawRequest("POST", jobPath, map[string]interface{}{
+		"reference":  "master",
+		"parameters": []string{},
+	})
+	var job lib.Job
+	require.NoError(t, res.Decode(&job), "invalid job creation response: %s", res.Body)
+	contract.Placeholder("job-id", job.ID)
+	bzk.RequireContract(contract, "job-start", "POST", jobPath, res)
+
+	jobStatus := bzk.WaitForJob(job.ID, 90*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	call("job-get", "GET", fmt.Sprintf("/job/%s", job.ID), nil)
+	call("job-get-unknown", "GET", "/job/000000000000000000000000", nil)
+	call("job-list", "GET", "/job", nil)
+	call("project-jobs", "GET", jobPath, nil)
+	call("job-log", "GET", fmt.Sprintf("/job/%s/log", job.ID), nil)
+
+	// variants
+	res = bzk.RawRequest("GET", fmt.Sprintf("/job/%s/variant", job.ID), nil)
+	var variants []lib.Variant
+	require.NoError(t, res.Decode(&variants), "invalid variants response: %s", res.Body)
+	require.Equal(t, http.StatusOK, res.Status)
+	require.Len(t, variants, 1, "Should have exactly one variant")
+	contract.Placeholder("variant-id", variants[0].ID)
+	bzk.RequireContract(contract, "job-variants", "GET", fmt.Sprintf("/job/%s/variant", job.ID), res)
+
+	call("variant-get", "GET", fmt.Sprintf("/variant/%s", variants[0].ID), nil)
+	call("variant-log", "GET", fmt.Sprintf("/variant/%s/log", variants[0].ID), nil)
+}
@@ -0,0 +1,7 @@
+package main
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	t.Fatalf("error: this test always fails")
+}
@@ -0,0 +1,62 @@
+#!/bin/sh
+# Prints what the build container looks like as a single JSON line prefixed with BZK-PROBE,
+# so that the e2e tests can extract it from the build log.
+# The build log is visible to anybody with access to the job: env values, secure ones included,
+# are only printed as their sha256
+
+# json_string prints its argument as a JSON string
+json_string() {
+	printf '"'
+	printf '%s' "$1" | sed -e 's/\\/\\\\/g' -e 's/"/\\"/g' -e 's/	/\\t/g' -e 's/\r/\\r/g' | awk 'NR > 1 { printf "\\n" } { printf "%s", $0 }'
+	printf '"'
+}
+
+# json_lines prints its standard input as a JSON array of strings, one per line
+json_lines() {
+	printf '['
+	first=1
+	while IFS= read -r line; do
+		[ $first -eq 1 ] || printf ','
+		first=0
+		json_string "$line"
+	done
+	printf ']'
+}
+
+# env_hashes prints the env as a JSON object mapping each variable name to the sha256 of its value
+env_hashes() {
+	printf '{'
+	first=1
+	# values spanning several lines can look like extra variables, which are skipped as they're not set
+	for name in $(env | sed -n 's/^\([A-Za-z_][A-Za-z0-9_]*\)=.*/\1/p' | sort -u); do
+		eval "[ -n \"\${$name+set}\" ]" || continue
+		[ $first -eq 1 ] || printf ','
+		first=0
+		printf '"%s":"%s"' "$name" "$(eval "printf '%s' \"\$$name\"" | sha256sum | cut -d' ' -f1)"
+	done
+	printf '}'
+}
+
+tool_version() {
+	case $1 in
+		go) go version ;;
+		java) java -version 2>&1 ;;
+		*) $1 --version 2>&1 ;;
+	esac | head -n 1
+}
+
+printf 'BZK-PROBE {'
+printf '"cwd":'; json_string "$(pwd)"
+printf ',"env":'; env_hashes
+printf ',"files":'; find . -path ./.git -prune -o -print | sort | json_lines
+printf ',"tools":{'
+first=1
+for tool in go git node npm python java mvn; do
+	if command -v $tool > /dev/null 2>&1; then
+		[ $first -eq 1 ] || printf ','
+		first=0
+		printf '"%s":' $tool
+		json_string "$(tool_version $tool)"
+	fi
+done
+printf '}}\n'
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	message := os.Getenv("MESSAGE")
+
+	decoded, err := base64.StdEncoding.DecodeString(os.Getenv("ENCODED"))
+	if err != nil {
+		t.Fatalf("Error: invalid base64 in ENCODED: %v", err)
+	}
+	if string(decoded) != message {
+		t.Fatalf("Error: wanted '%s', got '%s'", message, decoded)
+	}
+
+	sum := sha256.Sum256([]byte(message))
+	if digest := hex.EncodeToString(sum[:]); digest != os.Getenv("DIGEST") {
+		t.Fatalf("Error: wanted digest '%s', got '%s'", digest, os.Getenv("DIGEST"))
+	}
+}
@@ -0,0 +1,8 @@
+#!/bin/sh
+set -e
+
+# the symlink must have been committed as such, not as a copy of its target
+test -L config/current.yml
+grep -q "answer: 42" config/current.yml
+
+go test -v ./...
@@ -0,0 +1,9 @@
+package main
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	if false {
+		t.Fatalf("error")
+	}
+}
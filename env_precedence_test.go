@@ -0,0 +1,221 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	precedenceVar = "PRECEDENCE"
+)
+
+// envSource is one of the ways a variable can be given a value
+type envSource struct {
+	name string
+	// the values declared in .bazooka.yml, encrypted when secure
+	values []string
+	secure bool
+	// values excluded from the build matrix with a matrix.exclude entry
+	excluded []string
+	// whether it's a job parameter rather than a .bazooka.yml entry
+	param bool
+}
+
+// The project config is not one of the sources: the server only reads its own settings from it
+// (code host credentials, hook secrets, ...), none of them ends up in the build env (see TestEnvIgnoresProjectConfig)
+var envSources = []envSource{
+	{name: "plain", values: []string{"plain"}},
+	{name: "matrix", values: []string{"matrix-1", "matrix-2", "matrix-3"}, excluded: []string{"matrix-3"}},
+	{name: "secure", values: []string{"secure"}, secure: true},
+	{name: "param", values: []string{"param"}, param: true},
+}
+
+// envCombination is a subset of the env sources, all of them setting the same variable
+type envCombination []envSource
+
+func (c envCombination) String() string {
+	var names []string
+	for _, s := range c {
+		names = append(names, s.name)
+	}
+	return strings.Join(names, "+")
+}
+
+// expected returns the value the variable should have in each variant, according to the precedence rules:
+//   - every value declared in .bazooka.yml, plain, matrix or secure, is a matrix dimension, and gets its own variant
+//   - unless it's excluded from the matrix
+//   - a job parameter overrides the variable in every variant
+func (c envCombination) expected() []string {
+	excluded := map[string]bool{}
+	var param string
+	for _, s := range c {
+		if s.param {
+			param = s.values[0]
+		}
+		for _, v := range s.excluded {
+			excluded[v] = true
+		}
+	}
+
+	var declared []string
+	for _, s := range c {
+		for _, v := range s.values {
+			if !s.param && !excluded[v] {
+				declared = append(declared, v)
+			}
+		}
+	}
+
+	if len(declared) == 0 {
+		declared = []string{""}
+	}
+
+	res := make([]string, len(declared))
+	for i, v := range declared {
+		res[i] = v
+		if len(param) > 0 {
+			res[i] = param
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func envCombinations() []envCombination {
+	var res []envCombination
+	for mask := 1; mask < 1<<uint(len(envSources)); mask++ {
+		var c envCombination
+		for i, s := range envSources {
+			if mask&(1<<uint(i)) != 0 {
+				c = append(c, s)
+			}
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	type started struct {
+		combination envCombination
+		jobID       string
+	}
+	var jobs []started
+
+	for i, c := range envCombinations() {
+		var params []string
+		for _, s := range c {
+			if s.param {
+				for _, v := range s.values {
+					params = append(params, fmt.Sprintf("%s=%s", precedenceVar, v))
+				}
+			}
+		}
+
+		c := c
+		_, job := bzk.StartFixtureJob(&FixtureProject{
+			Name:     fmt.Sprintf("env-proj-%d", i),
+			Base:     "data/go-project",
+			Overlays: []string{probeOverlay},
+			Config: func(proj *lib.Project) *BzkConfig {
+				var env []Env
+				var matrix *Matrix
+				for _, s := range c {
+					for _, v := range s.excluded {
+						if matrix == nil {
+							matrix = &Matrix{}
+						}
+						matrix.Exclude = append(matrix.Exclude, map[string]interface{}{
+							"env": fmt.Sprintf("%s=%s", precedenceVar, v),
+						})
+					}
+					for _, v := range s.values {
+						switch {
+						case s.param:
+						case s.secure:
+							encrypted, err := bzk.Api.Project.EncryptData(proj.ID, fmt.Sprintf("%s=%s", precedenceVar, v))
+							require.NoError(t, err, "error while encrypting data")
+							env = append(env, SecureEnv(encrypted))
+						default:
+							env = append(env, EnvVar(precedenceVar, v))
+						}
+					}
+				}
+				return probeConfig(env, matrix)
+			},
+		}, params)
+		t.Logf("Started job %v for %v", job.ID, c)
+
+		jobs = append(jobs, started{c, job.ID})
+	}
+
+	// every combination is checked, so that a failing one doesn't hide the others
+	var mismatches []string
+	for _, j := range jobs {
+		jobStatus := bzk.WaitForJob(j.jobID, 120*time.Second)
+		if jobStatus != lib.JOB_SUCCESS {
+			mismatches = append(mismatches, fmt.Sprintf("%v: the job ended with status %v", j.combination, jobStatus))
+			continue
+		}
+
+		variants, err := bzk.Api.Job.Variants(j.jobID)
+		require.NoError(t, err, "error while listing job variants")
+
+		var candidates []string
+		for _, s := range j.combination {
+			candidates = append(candidates, s.values...)
+		}
+		var actual []string
+		for _, v := range variants {
+			actual = append(actual, bzk.VariantProbe(v.ID).EnvAmong(precedenceVar, candidates...))
+		}
+		sort.Strings(actual)
+
+		if expected := j.combination.expected(); !reflect.DeepEqual(expected, actual) {
+			mismatches = append(mismatches, fmt.Sprintf("%v: expected %s values %q, got %q", j.combination, precedenceVar, expected, actual))
+		}
+	}
+	if len(mismatches) > 0 {
+		t.Fatalf("%d of %d combinations failed:\n%s", len(mismatches), len(jobs), strings.Join(mismatches, "\n"))
+	}
+}
+
+func TestEnvIgnoresProjectConfig(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name:     "env-proj",
+		Base:     "data/go-project",
+		Overlays: []string{probeOverlay},
+		Config: func(proj *lib.Project) *BzkConfig {
+			// named like an env variable, so that it can't be told apart from one
+			err := bzk.Api.Project.Config.SetKey(proj.ID, precedenceVar, "project-config")
+			require.NoError(t, err, "error while setting the project config")
+			return probeConfig(nil, nil)
+		},
+	}, nil)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Equal(t, 1, len(variants), "Should have exactly one variant")
+
+	probe := bzk.VariantProbe(variants[0].ID)
+	require.False(t, probe.HasEnv(precedenceVar), "the project config should not end up in the build env")
+}
@@ -0,0 +1,189 @@
+package e2e
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lib "github.com/bazooka-ci/bazooka/commons"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	overlayPatchSuffix = ".patch"
+)
+
+// ImportFixture builds the repository content from a base fixture directory and a list of overlay directories,
+// applied in order on top of it.
+//
+// Overlay files replace the base ones, except for:
+//   - YAML files, which are merged by key into the existing ones (nested maps are merged recursively, anything else is replaced)
+//   - files ending with .patch, which are applied to the repository using git apply
+//   - the render manifest, which is appended to the existing one
+func (r *Repository) ImportFixture(base string, overlays ...string) {
+	r.ImportDir(base)
+	for _, overlay := range overlays {
+		r.importOverlay(overlay)
+	}
+}
+
+// FixtureProject describes a project building a fixture, see NewFixtureProject
+type FixtureProject struct {
+	Name     string
+	Base     string
+	Overlays []string
+	// if set, builds the .bazooka.yml replacing the fixture one.
+	// It's called once the project exists, so that data can be encrypted with its key
+	Config func(proj *lib.Project) *BzkConfig
+}
+
+// NewFixtureProject creates a repository holding the fixture (see ImportFixture) in a single commit, and a project building it
+func (b *Bzk) NewFixtureProject(f *FixtureProject) (*Repository, *lib.Project) {
+	repo := b.NewRepository()
+
+	proj, err := b.Api.Project.Create(f.Name, "git", repo.CloneURL())
+	if err != nil {
+		b.t.Fatalf("Error while creating the project %s: %v", f.Name, err)
+	}
+	b.t.Logf("Created project: %v", proj.ID)
+
+	repo.ImportFixture(f.Base, f.Overlays...)
+	if f.Config != nil {
+		repo.WriteConfig(f.Config(proj))
+	}
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	return repo, proj
+}
+
+// StartFixtureJob creates a fixture project (see NewFixtureProject) and starts a job on its master branch
+func (b *Bzk) StartFixtureJob(f *FixtureProject, params []string) (*Repository, *lib.Job) {
+	repo, proj := b.NewFixtureProject(f)
+
+	job, err := b.Api.Project.StartJob(proj.ID, "master", params)
+	if err != nil {
+		b.t.Fatalf("Error while starting a job on the project %s: %v", f.Name, err)
+	}
+	b.t.Logf("Started job: %v", job)
+
+	return repo, job
+}
+
+func (r *Repository) importOverlay(src string) {
+	r.t.Logf("Applying overlay %s to repository %d", src, r.index)
+	var patches []string
+	if err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := strings.TrimPrefix(path, src)
+		dst := filepath.Join(r.location, rel)
+		switch {
+		case path == src:
+			return nil
+		case info.IsDir():
+			return mkdirAs(dst, info)
+		case strings.HasSuffix(path, overlayPatchSuffix):
+			patches = append(patches, path)
+		case filepath.Base(path) == renderManifest:
+			return appendFileContents(path, dst)
+		case isYaml(path) && exists(dst):
+			return mergeYamlFile(path, dst)
+		default:
+			r.ImportFile(path, rel)
+		}
+		return nil
+	}); err != nil {
+		r.t.Fatalf("Error while applying overlay %s: %v", src, err)
+	}
+
+	for _, patch := range patches {
+		r.applyPatch(patch)
+	}
+}
+
+func (r *Repository) applyPatch(patch string) {
+	name := ".bzk-overlay" + overlayPatchSuffix
+	r.ImportFile(patch, name)
+	r.cmd("git", "apply", "--verbose", name)
+	if err := os.Remove(filepath.Join(r.location, name)); err != nil {
+		r.t.Fatalf("Error while removing the applied patch %s: %v", patch, err)
+	}
+}
+
+func isYaml(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yml" || ext == ".yaml"
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func appendFileContents(src, dst string) error {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = out.Write(append([]byte("\n"), b...))
+	return err
+}
+
+// mergeYamlFile merges the YAML document in src into the one in dst, src keys taking precedence
+func mergeYamlFile(src, dst string) error {
+	var overlay, base yaml.MapSlice
+	if err := readYamlFile(src, &overlay); err != nil {
+		return err
+	}
+	if err := readYamlFile(dst, &base); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(mergeYaml(base, overlay))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, out, 0644)
+}
+
+func readYamlFile(path string, out *yaml.MapSlice) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, out)
+}
+
+func mergeYaml(base, overlay yaml.MapSlice) yaml.MapSlice {
+	merged := append(yaml.MapSlice{}, base...)
+	for _, item := range overlay {
+		found := false
+		for i, existing := range merged {
+			if existing.Key != item.Key {
+				continue
+			}
+			found = true
+			baseMap, baseIsMap := existing.Value.(yaml.MapSlice)
+			overlayMap, overlayIsMap := item.Value.(yaml.MapSlice)
+			if baseIsMap && overlayIsMap {
+				merged[i].Value = mergeYaml(baseMap, overlayMap)
+			} else {
+				merged[i].Value = item.Value
+			}
+			break
+		}
+		if !found {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
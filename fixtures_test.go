@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFixtureOverlays(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+
+	proj, err := bzk.Api.Project.Create("overlay-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	encryptedData, err := bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+	require.NoError(t, err, "error while encrypting data")
+
+	repo.ImportFixture("data/go-project", "data/overlays/with-secure-env", "data/overlays/failing-test")
+	repo.RenderAll(map[string]interface{}{
+		"Secure": encryptedData,
+	})
+
+	// the overlay env is merged into the base config instead of replacing it
+	config, err := ioutil.ReadFile(filepath.Join(repo.location, ".bazooka.yml"))
+	require.NoError(t, err, "error while reading the merged config")
+	require.Equal(t, "language: golang\ngo:\n- \"1.4\"\nenv:\n- secure: '"+encryptedData+"'\n", string(config))
+
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+
+	require.Equal(t, lib.JOB_FAILED, jobStatus)
+}
+
+func TestFixturePatchOverlay(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportFixture("data/go-project", "data/overlays/failing-patch")
+
+	// the patch itself is not part of the repository, its changes are
+	require.False(t, exists(filepath.Join(repo.location, "always-fail.patch")), "the patch should not be imported as a file")
+	source, err := ioutil.ReadFile(filepath.Join(repo.location, "main_test.go"))
+	require.NoError(t, err, "error while reading the patched file")
+	require.Contains(t, string(source), "patched to always fail")
+
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("patch-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+
+	require.Equal(t, lib.JOB_FAILED, jobStatus)
+	require.Contains(t, bzk.JobLog(job.ID), "patched to always fail")
+}
+
+func TestMergeYaml(t *testing.T) {
+	recordCompat(t)
+	var base, overlay yaml.MapSlice
+	require.NoError(t, yaml.Unmarshal([]byte(`
+language: golang
+go:
+  - "1.3"
+  - "1.4"
+services:
+  mongo:
+    image: mongo
+    ports: [27017]
+script: make
+`), &base))
+	require.NoError(t, yaml.Unmarshal([]byte(`
+script: make test
+services:
+  mongo:
+    image: mongo:3.0.2
+  redis:
+    image: redis
+go:
+  - "1.5"
+env:
+  - A=1
+`), &overlay))
+
+	out, err := yaml.Marshal(mergeYaml(base, overlay))
+	require.NoError(t, err, "error while encoding the merged yaml")
+
+	// base keys keep their order, new keys come last, maps are merged and lists replaced
+	require.Equal(t, `language: golang
+go:
+- "1.5"
+services:
+  mongo:
+    image: mongo:3.0.2
+    ports:
+    - 27017
+  redis:
+    image: redis
+script: make test
+env:
+- A=1
+`, string(out))
+}
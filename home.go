@@ -0,0 +1,199 @@
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	dockercmd "github.com/bywan/go-dockercommand"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// the layout of the bazooka home, as mounted in the server container
+const (
+	// each job gets its own directory: build/<project id>/<job id>
+	homeBuildDir = "build"
+	// the checked out sources and the per variant workspaces (with their generated Dockerfile) of a job
+	homeSourceDir = "source"
+	homeWorkDir   = "work"
+	// the log files of a job (its own and its variants ones), kept once the job completes
+	homeLogsDir   = "logs"
+	homeLogSuffix = ".log"
+	// the per project keys
+	homeKeysDir = "keys"
+
+	// where the bazooka home is mounted in the containers inspecting it
+	homeMountPoint = "/bzk-home"
+)
+
+// HomeEntry is a file or directory found in the bazooka home
+type HomeEntry struct {
+	// relative to the bazooka home
+	Path string
+	Mode os.FileMode
+	Size int64
+}
+
+func (e HomeEntry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// JobHome returns the directory of a job, relative to the bazooka home
+func JobHome(projectID, jobID string) string {
+	return filepath.Join(homeBuildDir, projectID, jobID)
+}
+
+// HomeEntries lists everything under dir, relative to the bazooka home.
+// It returns nothing if dir doesn't exist
+func (b *Bzk) HomeEntries(dir string) []HomeEntry {
+	return b.findInHome(dir, "-mindepth", "1")
+}
+
+// HomeExists tells whether the path, relative to the bazooka home, exists
+func (b *Bzk) HomeExists(path string) bool {
+	return len(b.findInHome(path, "-maxdepth", "0")) > 0
+}
+
+// findInHome runs find on path, relative to the bazooka home, in a helper container:
+// the server writes the home as root, the test process may not be allowed to read it
+func (b *Bzk) findInHome(path string, args ...string) []HomeEntry {
+	root := filepath.Join(homeMountPoint, path)
+	// a NUL terminated record per entry: type, octal permissions, size and path
+	script := `if [ -e "$1" ] || [ -L "$1" ]; then find "$@" -printf '%y %m %s %p\0'; fi`
+	container, err := b.dockerClient.Run(&dockercmd.RunOptions{
+		Image: cleanupImage,
+		VolumeBinds: []string{
+			fmt.Sprintf("%s:%s:ro", b.bzkHome, homeMountPoint),
+		},
+		Cmd: append([]string{"sh", "-c", script, "find", root}, args...),
+	})
+	if err != nil {
+		b.t.Fatalf("Failed to start a container to inspect the bazooka home %s: %v", path, err)
+	}
+	defer container.Remove(&dockercmd.RemoveOptions{
+		Force:         true,
+		RemoveVolumes: true,
+	})
+
+	exitCode, err := container.Wait()
+	if err != nil {
+		b.t.Fatalf("Failed to retrieve the exit code of the container inspecting the bazooka home %s: %v", path, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := b.docker.Logs(docker.LogsOptions{
+		Container:    container.ID(),
+		OutputStream: &stdout,
+		ErrorStream:  &stderr,
+		Stdout:       true,
+		Stderr:       true,
+	}); err != nil {
+		b.t.Fatalf("Failed to retrieve the output of the container inspecting the bazooka home %s: %v", path, err)
+	}
+	if exitCode != 0 {
+		b.t.Fatalf("Failed to inspect the bazooka home %s: exit code %d: %s", path, exitCode, stderr.String())
+	}
+
+	var entries []HomeEntry
+	for _, line := range strings.Split(stdout.String(), "\x00") {
+		if len(line) == 0 {
+			continue
+		}
+		entry, err := parseHomeEntry(line)
+		if err != nil {
+			b.t.Fatalf("Failed to inspect the bazooka home %s: %v", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseHomeEntry parses a line of find -printf '%y %m %s %p'
+func parseHomeEntry(line string) (HomeEntry, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 {
+		return HomeEntry{}, fmt.Errorf("unexpected find output %q", line)
+	}
+
+	perm, err := strconv.ParseUint(fields[1], 8, 32)
+	if err != nil {
+		return HomeEntry{}, fmt.Errorf("invalid mode in %q: %v", line, err)
+	}
+	mode := os.FileMode(perm) & os.ModePerm
+	for bit, flag := range map[uint64]os.FileMode{04000: os.ModeSetuid, 02000: os.ModeSetgid, 01000: os.ModeSticky} {
+		if perm&bit != 0 {
+			mode |= flag
+		}
+	}
+	switch fields[0] {
+	case "f":
+	case "d":
+		mode |= os.ModeDir
+	case "l":
+		mode |= os.ModeSymlink
+	default:
+		mode |= os.ModeIrregular
+	}
+
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return HomeEntry{}, fmt.Errorf("invalid size in %q: %v", line, err)
+	}
+	rel, err := filepath.Rel(homeMountPoint, fields[3])
+	if err != nil {
+		return HomeEntry{}, err
+	}
+
+	return HomeEntry{
+		Path: rel,
+		Mode: mode,
+		Size: size,
+	}, nil
+}
+
+// HomeUsage returns the total size of the files under dir, relative to the bazooka home
+func (b *Bzk) HomeUsage(dir string) int64 {
+	var size int64
+	for _, e := range b.HomeEntries(dir) {
+		if !e.IsDir() {
+			size += e.Size
+		}
+	}
+	return size
+}
+
+// FindHomeEntries returns the entries under dir whose base name is name
+func (b *Bzk) FindHomeEntries(dir, name string) []HomeEntry {
+	var res []HomeEntry
+	for _, e := range b.HomeEntries(dir) {
+		if filepath.Base(e.Path) == name {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// logFiles returns the log files among the entries
+func logFiles(entries []HomeEntry) []HomeEntry {
+	var res []HomeEntry
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Path) == homeLogSuffix {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// worldWritable returns the entries anybody can write to, symlinks excluded
+func worldWritable(entries []HomeEntry) []string {
+	var res []string
+	for _, e := range entries {
+		if e.Mode&os.ModeSymlink == 0 && e.Mode.Perm()&0002 != 0 {
+			res = append(res, e.Path)
+		}
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHomeLayout(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("home-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", []string{"SLEEP=10"})
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	bzk.WaitForJobLog(job.ID, sleeperStarted, 60*time.Second)
+
+	// while the job runs, its workspace is there
+	jobHome := JobHome(proj.ID, job.ID)
+	require.True(t, bzk.HomeExists(jobHome), "the job should have its own directory")
+	require.True(t, bzk.HomeExists(filepath.Join(jobHome, homeSourceDir, "main.go")), "the sources should be checked out in the job directory")
+	require.NotEmpty(t, bzk.FindHomeEntries(filepath.Join(jobHome, homeWorkDir), "Dockerfile"), "the variant Dockerfile should be generated in the job workspace")
+	require.Empty(t, worldWritable(bzk.HomeEntries(jobHome)), "nothing in the job directory should be world writable")
+
+	// so are its logs, which may contain decrypted secrets
+	jobLogs := filepath.Join(jobHome, homeLogsDir)
+	logs := logFiles(bzk.HomeEntries(jobLogs))
+	require.NotEmpty(t, logs, "the job logs should be written in the job directory")
+	for _, l := range logs {
+		require.Zero(t, l.Mode.Perm()&0007, "the log %s should not be readable by others, got %v", l.Path, l.Mode)
+	}
+
+	// the project key is private
+	keys := bzk.HomeEntries(homeKeysDir)
+	require.NotEmpty(t, keys, "the project should have a key")
+	for _, k := range keys {
+		if !k.IsDir() {
+			require.Zero(t, k.Mode.Perm()&0077, "the key %s should only be readable by its owner, got %v", k.Path, k.Mode)
+		}
+	}
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	// once the job is done, its workspace is cleaned up
+	require.False(t, bzk.HomeExists(filepath.Join(jobHome, homeSourceDir)), "the job sources should be removed once the job completes")
+	require.False(t, bzk.HomeExists(filepath.Join(jobHome, homeWorkDir)), "the job workspace should be removed once the job completes")
+	require.NotEmpty(t, logFiles(bzk.HomeEntries(jobLogs)), "the job logs should be kept once the job completes")
+	t.Logf("%d bytes left in %s after the job completed", bzk.HomeUsage(jobHome), jobHome)
+}
+
+func TestHomeCleanupOnProjectDeletion(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("home-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	_, err = bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+	require.NoError(t, err, "error while encrypting data")
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	projectHome := filepath.Join(homeBuildDir, proj.ID)
+	require.True(t, bzk.HomeExists(projectHome), "the project should have its own directory")
+	require.NotEmpty(t, logFiles(bzk.HomeEntries(filepath.Join(JobHome(proj.ID, job.ID), homeLogsDir))), "the job logs should be kept once the job completes")
+
+	res := bzk.DeleteProject(proj.ID)
+	require.Equal(t, http.StatusNoContent, res.Status, "project deletion failed: %s", res.Body)
+
+	require.False(t, bzk.HomeExists(projectHome), "the project directory, job logs included, should be removed with the project")
+	require.Empty(t, bzk.FindHomeEntries(homeKeysDir, proj.ID), "the project key should be removed with the project")
+	require.Zero(t, bzk.HomeUsage(homeBuildDir), "nothing should be left in the build directory")
+}
@@ -0,0 +1,178 @@
+package e2e
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	// the project config key holding the secret used to sign the github hooks payloads
+	githubSecretConfigKey = "github.secret"
+)
+
+// PushEvent describes the push of a commit to a branch, as notified by a code host
+type PushEvent struct {
+	Branch   string
+	SHA      string
+	Message  string
+	CloneURL string
+
+	t *testing.T
+}
+
+// PushEvent describes the push of the current head of branch
+func (r *Repository) PushEvent(branch string) *PushEvent {
+	return &PushEvent{
+		Branch:   branch,
+		SHA:      r.GitRevision(branch),
+		Message:  r.lastCommitMessage,
+		CloneURL: r.CloneURL(),
+		t:        r.t,
+	}
+}
+
+// GithubPayload builds a github push webhook payload
+func (e *PushEvent) GithubPayload() []byte {
+	now := time.Now().Format(time.RFC3339)
+	author := map[string]string{
+		"name":     "Squirrel Holding-a-Bazooka",
+		"email":    "squirrel@bazooka-ci.io",
+		"username": "squirrel",
+	}
+	commit := map[string]interface{}{
+		"id":        e.SHA,
+		"distinct":  true,
+		"message":   e.Message,
+		"timestamp": now,
+		"url":       fmt.Sprintf("%scommit/%s", e.CloneURL, e.SHA),
+		"author":    author,
+		"committer": author,
+		"added":     []string{},
+		"removed":   []string{},
+		"modified":  []string{},
+	}
+
+	return e.marshal(map[string]interface{}{
+		"ref":         fmt.Sprintf("refs/heads/%s", e.Branch),
+		"before":      strings.Repeat("0", 40),
+		"after":       e.SHA,
+		"created":     false,
+		"deleted":     false,
+		"forced":      false,
+		"compare":     e.CloneURL,
+		"commits":     []interface{}{commit},
+		"head_commit": commit,
+		"repository": map[string]interface{}{
+			"name":      "e2e",
+			"full_name": "bazooka-ci/e2e",
+			"url":       e.CloneURL,
+			"git_url":   e.CloneURL,
+			"clone_url": e.CloneURL,
+		},
+		"pusher": map[string]string{
+			"name":  author["username"],
+			"email": author["email"],
+		},
+	})
+}
+
+// BitbucketPayload builds a bitbucket POST service payload
+func (e *PushEvent) BitbucketPayload() []byte {
+	return e.marshal(map[string]interface{}{
+		"canon_url": "https://bitbucket.org",
+		"user":      "squirrel",
+		"commits": []interface{}{
+			map[string]interface{}{
+				"node":         e.SHA[:12],
+				"raw_node":     e.SHA,
+				"branch":       e.Branch,
+				"branches":     []string{e.Branch},
+				"message":      e.Message,
+				"author":       "squirrel",
+				"raw_author":   "Squirrel Holding-a-Bazooka <squirrel@bazooka-ci.io>",
+				"timestamp":    time.Now().Format("2006-01-02 15:04:05"),
+				"utctimestamp": time.Now().UTC().Format("2006-01-02 15:04:05+00:00"),
+				"parents":      []string{},
+				"files":        []string{},
+				"size":         -1,
+			},
+		},
+		"repository": map[string]interface{}{
+			"absolute_url": "/bazooka-ci/e2e/",
+			"name":         "e2e",
+			"owner":        "bazooka-ci",
+			"slug":         "e2e",
+			"scm":          "git",
+			"is_private":   false,
+			"website":      e.CloneURL,
+		},
+		"truncated": false,
+	})
+}
+
+// GithubHookURL returns the project's github hook endpoint, as reachable from docker containers
+func (b *Bzk) GithubHookURL(projectID string) string {
+	return fmt.Sprintf("%s/project/%s/github", b.serverURL, projectID)
+}
+
+// NotifyGithubPush POSTs a github push webhook for the event to the project's hook endpoint.
+// If secret is not empty, the payload is signed with it the way github does
+func (b *Bzk) NotifyGithubPush(projectID string, e *PushEvent, secret string) int {
+	payload := e.GithubPayload()
+	headers := map[string]string{
+		"Content-Type":   "application/json",
+		"X-GitHub-Event": "push",
+	}
+	if len(secret) > 0 {
+		mac := hmac.New(sha1.New, []byte(secret))
+		mac.Write(payload)
+		headers["X-Hub-Signature"] = "sha1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return b.postHook(b.GithubHookURL(projectID), payload, headers)
+}
+
+// NotifyBitbucketPush POSTs a bitbucket push notification for the event to the project's hook endpoint
+func (b *Bzk) NotifyBitbucketPush(projectID string, e *PushEvent) int {
+	form := url.Values{"payload": {string(e.BitbucketPayload())}}
+	return b.postHook(fmt.Sprintf("%s/project/%s/bitbucket", b.serverURL, projectID), []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+}
+
+func (b *Bzk) postHook(endpoint string, payload []byte, headers map[string]string) int {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		b.t.Fatalf("Failed to create the hook request for %s: %v", endpoint, err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		b.t.Fatalf("Failed to post the hook to %s: %v", endpoint, err)
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	b.t.Logf("Posted hook to %s: %s %s", endpoint, res.Status, body)
+	return res.StatusCode
+}
+
+func (e *PushEvent) marshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		e.t.Fatalf("Failed to encode the hook payload: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+const (
+	hookSecret = "Ze Secret"
+)
+
+func TestGithubPushHook(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("github-hook-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	err = bzk.Api.Project.Config.SetKey(proj.ID, githubSecretConfigKey, hookSecret)
+	require.NoError(t, err, "error while setting the github secret")
+
+	event := repo.PushEvent("master")
+	status := bzk.NotifyGithubPush(proj.ID, event, hookSecret)
+	require.True(t, status >= 200 && status < 300, "the github hook was rejected with status %d", status)
+
+	requirePushedJob(t, bzk, proj.ID, event)
+}
+
+func TestGithubPushHookOnBranch(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+	repo.GitCheckoutNewBranch("feature")
+	repo.ImportFile("data/params-project/main.go", "main.go")
+	repo.GitAddAll()
+	repo.GitCommit("Feature work")
+
+	proj, err := bzk.Api.Project.Create("github-branch-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	event := repo.PushEvent("feature")
+	require.NotEqual(t, repo.GitRevision("master"), event.SHA, "the feature branch should have its own commit")
+
+	status := bzk.NotifyGithubPush(proj.ID, event, "")
+	require.True(t, status >= 200 && status < 300, "the github hook was rejected with status %d", status)
+
+	requirePushedJob(t, bzk, proj.ID, event)
+}
+
+func TestBitbucketPushHook(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("bitbucket-hook-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	event := repo.PushEvent("master")
+	status := bzk.NotifyBitbucketPush(proj.ID, event)
+	require.True(t, status >= 200 && status < 300, "the bitbucket hook was rejected with status %d", status)
+
+	requirePushedJob(t, bzk, proj.ID, event)
+}
+
+// requirePushedJob ensures a job was started for the pushed branch and commit, and that it succeeded
+func requirePushedJob(t *testing.T, bzk *Bzk, projectID string, event *PushEvent) {
+	job := bzk.WaitForProjectJob(projectID, 1, 20*time.Second)
+	t.Logf("Hook started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	job, err := bzk.Api.Job.Get(job.ID)
+	require.NoError(t, err, "error while getting the job")
+	require.Equal(t, event.Branch, job.SCMMetadata.Reference, "the job should have built the pushed branch")
+	require.Equal(t, event.SHA, job.SCMMetadata.CommitID, "the job should have built the pushed commit")
+}
@@ -0,0 +1,80 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExecutableScriptAndSymlink(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/script-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("script-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
+
+func TestImportModes(t *testing.T) {
+	recordCompat(t)
+	root, err := ioutil.TempDir(tempDir, "bazooka-import")
+	require.NoError(t, err, "error while creating a temp dir")
+	defer os.RemoveAll(root)
+
+	// git can't carry empty directories, so the fixtures are created here rather than checked in
+	base := filepath.Join(root, "base")
+	overlay := filepath.Join(root, "overlay")
+	for dir, mode := range map[string]os.FileMode{
+		filepath.Join(base, "empty"):     0777,
+		filepath.Join(base, "private"):   0700,
+		filepath.Join(overlay, "shared"): 0777,
+	} {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		// explicitly, as MkdirAll is subject to the umask
+		require.NoError(t, os.Chmod(dir, mode))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(base, "private", "script.sh"), []byte("#!/bin/sh\n"), 0750))
+	require.NoError(t, os.Symlink("private/script.sh", filepath.Join(base, "link")))
+
+	repo := &Repository{
+		t:        t,
+		location: filepath.Join(root, "repo"),
+	}
+	require.NoError(t, os.Mkdir(repo.location, 0755))
+	repo.ImportFixture(base, overlay)
+
+	for path, mode := range map[string]os.FileMode{
+		"empty":             os.ModeDir | 0777,
+		"private":           os.ModeDir | 0700,
+		"private/script.sh": 0750,
+		"shared":            os.ModeDir | 0777,
+	} {
+		info, err := os.Lstat(filepath.Join(repo.location, path))
+		require.NoError(t, err, "%s was not imported", path)
+		require.Equal(t, mode, info.Mode(), "wrong mode for %s", path)
+	}
+
+	target, err := os.Readlink(filepath.Join(repo.location, "link"))
+	require.NoError(t, err, "the symlink was not imported as a symlink")
+	require.Equal(t, "private/script.sh", target)
+}
@@ -1,12 +1,23 @@
 package e2e
 
 import (
+	"bufio"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
+var (
+	tagsFlag     = flag.String("bzk.tags", "", "comma separated list of bazooka/server tags to run the tests against")
+	tagsFileFlag = flag.String("bzk.tags-file", "", "file listing the bazooka/server tags to run the tests against, one per line")
+	goldenFlag   = flag.Bool("bzk.update-golden", false, "rewrite the API contract golden files with the responses of the server")
+)
+
 func TestMain(m *testing.M) {
+	flag.Parse()
+
 	tempDir = os.Getenv("BZK_E2E_TEMP")
 	if len(tempDir) == 0 {
 		fmt.Printf("$BZK_E2E_TEMP must be set to the location which will be used by the tests as a temporary bazooka home\n")
@@ -25,5 +36,58 @@ func TestMain(m *testing.M) {
 		os.Exit(-1)
 	}
 
-	os.Exit(m.Run())
+	strictLeaks = len(os.Getenv("BZK_E2E_STRICT_LEAKS")) > 0
+	updateGolden = *goldenFlag
+
+	tags, err := serverTags()
+	if err != nil {
+		fmt.Printf("Invalid server tags: %v\n", err)
+		os.Exit(-1)
+	}
+	if len(tags) == 0 {
+		os.Exit(m.Run())
+	}
+
+	exitCode := 0
+	for _, tag := range tags {
+		fmt.Printf("Running the tests against bazooka/server:%s\n", tag)
+		serverTag = tag
+		compat.addTag(tag)
+		if code := m.Run(); code != 0 {
+			exitCode = code
+		}
+	}
+
+	fmt.Printf("\nCompatibility matrix:\n")
+	compat.write(os.Stdout)
+
+	os.Exit(exitCode)
+}
+
+// serverTags returns the tags listed by the -bzk.tags and -bzk.tags-file flags, in order
+func serverTags() ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(*tagsFlag, ",") {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(*tagsFileFlag) == 0 {
+		return tags, nil
+	}
+
+	f, err := os.Open(*tagsFileFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if tag := strings.TrimSpace(scanner.Text()); len(tag) > 0 && !strings.HasPrefix(tag, "#") {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, scanner.Err()
 }
@@ -26,19 +26,35 @@ type Bzk struct {
 
 	t *testing.T
 
+	serverURL string
+
 	tag        string
 	bzkHome    string
 	dockerSock string
 	scmKey     string
 
 	dockerClient    *dockercmd.Docker
+	docker          *docker.Client
 	mongoContainer  *dockercmd.Container
 	serverContainer *dockercmd.Container
 
-	repos []*Repository
+	// extra environment variables passed to the server container
+	serverEnv map[string]string
+
+	repos     []*Repository
+	codeHosts []*FakeCodeHost
+	proxies   []*TCPProxy
+
+	dockerBefore *dockerSnapshot
 }
 
+// NewBazooka starts a bazooka instance using the bazooka/server tag the tests are run against
 func NewBazooka(t *testing.T) *Bzk {
+	return NewBazookaWithTag(t, serverTag)
+}
+
+// NewBazookaWithTag starts a bazooka instance using the given tag of the bazooka/server image
+func NewBazookaWithTag(t *testing.T, tag string) *Bzk {
 	bzkHome := path.Join(tempDir, "bazooka-home")
 
 	if err := os.MkdirAll(bzkHome, 0755); err != nil {
@@ -51,42 +67,53 @@ func NewBazooka(t *testing.T) *Bzk {
 		t.Fatalf("Failed to create a docker client: %v", err)
 	}
 
+	// like dockercmd, follows $DOCKER_HOST and the TLS settings: dockerSock is the socket path on the docker host
+	rawDockerClient, err := docker.NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to create a raw docker client: %v", err)
+	}
+
 	bzk := &Bzk{
 		t:            t,
-		tag:          "latest",
+		tag:          tag,
 		bzkHome:      bzkHome,
 		dockerSock:   dockerSock,
 		scmKey:       "",
+		serverEnv:    map[string]string{},
 		dockerClient: dockerClient,
+		docker:       rawDockerClient,
 	}
 
+	bzk.dockerBefore = bzk.snapshotDocker()
+
 	bzk.startMongo()
 	bzk.startServer()
 
-	serverPort := bzk.getHostPort(bzk.serverContainer, "3000/tcp")
+	bzk.connect()
+
+	return bzk
+}
+
+// connect waits for the API server to accept connections and (re)creates the API client
+func (b *Bzk) connect() {
+	serverPort := b.getHostPort(b.serverContainer, "3000/tcp")
 
 	timeout := 20 * time.Second
 	if err := lib.WaitForTcpConnection(serverHost, serverPort, 100*time.Millisecond, timeout); err != nil {
-		t.Fatalf("Couldn't connect to the bazooka API server on %s:%s after %v", serverHost, serverPort, timeout)
+		b.t.Fatalf("Couldn't connect to the bazooka API server on %s:%s after %v", serverHost, serverPort, timeout)
 	}
 
+	b.serverURL = fmt.Sprintf("http://%s:%s", serverHost, serverPort)
 	bzkApi, err := client.New(&client.Config{
-		URL: fmt.Sprintf("http://%s:%s", serverHost, serverPort),
+		URL: b.serverURL,
 	})
 	if err != nil {
-		t.Fatalf("Failed to create a bazooka API client: %v", err)
+		b.t.Fatalf("Failed to create a bazooka API client: %v", err)
 	}
-	bzk.Api = bzkApi
-
-	return bzk
+	b.Api = bzkApi
 }
 
 func (b *Bzk) Teardown() {
-	b.t.Logf("Deleting the bazooka home directory: %s", b.bzkHome)
-	if err := os.RemoveAll(b.bzkHome); err != nil {
-		b.t.Errorf("Error while deleting bazooka home directory: %v", err)
-	}
-
 	b.t.Logf("Removing the mongo container")
 	if err := b.serverContainer.Remove(&dockercmd.RemoveOptions{
 		Force:         true,
@@ -103,10 +130,29 @@ func (b *Bzk) Teardown() {
 		b.t.Errorf("Error while stopping mongo container: %v", err)
 	}
 
+	// only once the server is gone, so that it doesn't write anything more in it
+	b.t.Logf("Deleting the bazooka home directory: %s", b.bzkHome)
+	if err := removeAll(b.t, b.dockerClient, b.bzkHome); err != nil {
+		b.t.Errorf("Error while deleting bazooka home directory: %v", err)
+	}
+
 	b.t.Logf("Tearing down repositories")
 	for _, r := range b.repos {
 		r.teardown()
 	}
+
+	b.t.Logf("Closing proxies")
+	for _, p := range b.proxies {
+		p.Close()
+	}
+
+	b.t.Logf("Tearing down fake code hosts")
+	for _, h := range b.codeHosts {
+		h.teardown()
+	}
+
+	b.t.Logf("Checking for leaked docker resources")
+	b.checkLeaks(b.dockerBefore)
 }
 
 func (b *Bzk) startServer() {
@@ -118,6 +164,9 @@ func (b *Bzk) startServer() {
 	if len(b.scmKey) > 0 {
 		envMap["BZK_SCM_KEYFILE"] = b.scmKey
 	}
+	for k, v := range b.serverEnv {
+		envMap[k] = v
+	}
 
 	container, err := b.dockerClient.Run(&dockercmd.RunOptions{
 		Image:  fmt.Sprintf("bazooka/server:%s", b.tag),
@@ -141,8 +190,9 @@ func (b *Bzk) startServer() {
 func (b *Bzk) startMongo() {
 	b.t.Logf("Starting a mongodb instance")
 	container, err := b.dockerClient.Run(&dockercmd.RunOptions{
-		Image:  "mongo:3.0.2",
-		Detach: true,
+		Image:           "mongo:3.0.2",
+		Detach:          true,
+		PublishAllPorts: true,
 	})
 	if err != nil {
 		b.t.Fatalf("Failed to create a mongodb container: %v", err)
@@ -0,0 +1,76 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+var (
+	// a server waiting on an unresponsive database should still answer eventually
+	rawClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// APIResponse is a response of the bazooka API, as received on the wire
+type APIResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// RawRequest sends a request to the bazooka API bypassing the client, so that status codes and payloads can be asserted.
+// body, if not nil, is sent JSON-encoded
+func (b *Bzk) RawRequest(method, path string, body interface{}) *APIResponse {
+	res, err := b.TryRawRequest(method, path, body)
+	if err != nil {
+		b.t.Fatalf("Failed to send the request %s %s: %v", method, path, err)
+	}
+	return res
+}
+
+// TryRawRequest is like RawRequest, but returns the transport errors (timeouts, refused connections, ...) instead of failing the test
+func (b *Bzk) TryRawRequest(method, path string, body interface{}) (*APIResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			b.t.Fatalf("Failed to encode the body of %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, b.serverURL+path, reader)
+	if err != nil {
+		b.t.Fatalf("Failed to create the request %s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := rawClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	payload, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	b.t.Logf("%s %s: %s", method, path, res.Status)
+
+	return &APIResponse{
+		Status: res.StatusCode,
+		Header: res.Header,
+		Body:   payload,
+	}, nil
+}
+
+// Decode decodes the JSON response body into v
+func (r *APIResponse) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
@@ -1,11 +1,18 @@
 package e2e
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	lib "github.com/bazooka-ci/bazooka/commons"
 )
 
+const (
+	// the status the server reports for cancelled jobs and variants
+	jobCancelled lib.JobStatus = "CANCELLED"
+)
+
 func (b *Bzk) WaitForJob(jobID string, timeoutAfter time.Duration) lib.JobStatus {
 	b.t.Logf("Waiting for job %s", jobID)
 
@@ -29,3 +36,121 @@ func (b *Bzk) WaitForJob(jobID string, timeoutAfter time.Duration) lib.JobStatus
 		}
 	}
 }
+
+func (b *Bzk) JobLog(jobID string) string {
+	entries, err := b.Api.Job.Log(jobID)
+	if err != nil {
+		b.t.Fatalf("Error while getting the job %s log: %v", jobID, err)
+	}
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.Message
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WaitForProjectJob waits for the project to have at least count jobs, and returns the most recent one
+func (b *Bzk) WaitForProjectJob(projectID string, count int, timeoutAfter time.Duration) *lib.Job {
+	b.t.Logf("Waiting for job #%d of project %s", count, projectID)
+
+	giveUp := time.After(timeoutAfter)
+
+	for {
+		select {
+		case <-time.After(500 * time.Millisecond):
+			jobs, err := b.Api.Project.Jobs(projectID)
+			if err != nil {
+				b.t.Fatalf("Error while listing the project %s jobs: %v", projectID, err)
+			}
+			if len(jobs) < count {
+				continue
+			}
+			latest := jobs[0]
+			for _, j := range jobs {
+				if j.Number > latest.Number {
+					latest = j
+				}
+			}
+			return &latest
+
+		case <-giveUp:
+			b.t.Fatalf("Gave up waiting on job #%d of project %s: not started after %v", count, projectID, timeoutAfter)
+		}
+	}
+}
+
+// CreateProject creates a project through the raw API, for the cases where the response status code matters
+func (b *Bzk) CreateProject(name, scmType, scmURI string) *APIResponse {
+	return b.RawRequest("POST", "/project", map[string]string{
+		"name":     name,
+		"scm_type": scmType,
+		"scm_uri":  scmURI,
+	})
+}
+
+// UpdateProject updates the given fields (name, scm_type, scm_uri) of a project
+func (b *Bzk) UpdateProject(projectID string, fields map[string]string) *APIResponse {
+	return b.RawRequest("PUT", fmt.Sprintf("/project/%s", projectID), fields)
+}
+
+func (b *Bzk) DeleteProject(projectID string) *APIResponse {
+	return b.RawRequest("DELETE", fmt.Sprintf("/project/%s", projectID), nil)
+}
+
+// CancelJob asks the server to cancel a job
+func (b *Bzk) CancelJob(jobID string) *APIResponse {
+	b.t.Logf("Cancelling job %s", jobID)
+	return b.RawRequest("POST", fmt.Sprintf("/job/%s/cancel", jobID), nil)
+}
+
+// WaitForJobLog waits for the job log to contain the given text, and returns the whole log
+func (b *Bzk) WaitForJobLog(jobID, text string, timeoutAfter time.Duration) string {
+	b.t.Logf("Waiting for job %s to log %q", jobID, text)
+
+	giveUp := time.After(timeoutAfter)
+
+	for {
+		select {
+		case <-time.After(500 * time.Millisecond):
+			if log := b.JobLog(jobID); strings.Contains(log, text) {
+				return log
+			}
+
+		case <-giveUp:
+			b.t.Fatalf("Gave up waiting on job %s: didn't log %q after %v", jobID, text, timeoutAfter)
+		}
+	}
+}
+
+// WaitForAPI waits for the API to answer successfully again, after an outage for example
+func (b *Bzk) WaitForAPI(timeoutAfter time.Duration) {
+	b.t.Logf("Waiting for the API to answer")
+
+	giveUp := time.After(timeoutAfter)
+
+	for {
+		select {
+		case <-time.After(500 * time.Millisecond):
+			if res, err := b.TryRawRequest("GET", "/project", nil); err == nil && res.Status == 200 {
+				return
+			}
+
+		case <-giveUp:
+			b.t.Fatalf("Gave up waiting on the API: not answering after %v", timeoutAfter)
+		}
+	}
+}
+
+func (b *Bzk) VariantLog(variantID string) string {
+	entries, err := b.Api.Variant.Log(variantID)
+	if err != nil {
+		b.t.Fatalf("Error while getting the variant %s log: %v", variantID, err)
+	}
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.Message
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Containers returns the containers currently known to the docker daemon, running or not, indexed by their ids
+func (b *Bzk) Containers() map[string]docker.APIContainers {
+	containers, err := b.docker.ListContainers(docker.ListContainersOptions{All: true})
+	if err != nil {
+		b.t.Fatalf("Failed to list the docker containers: %v", err)
+	}
+
+	res := make(map[string]docker.APIContainers, len(containers))
+	for _, c := range containers {
+		res[c.ID] = c
+	}
+	return res
+}
+
+// WaitForContainersGone waits for all the containers created after the before snapshot (see Containers) to be removed,
+// and fails the test with the ones left otherwise
+func (b *Bzk) WaitForContainersGone(before map[string]docker.APIContainers, timeoutAfter time.Duration) {
+	giveUp := time.After(timeoutAfter)
+
+	for {
+		select {
+		case <-time.After(500 * time.Millisecond):
+			if len(newContainers(before, b.Containers())) == 0 {
+				return
+			}
+
+		case <-giveUp:
+			for _, c := range newContainers(before, b.Containers()) {
+				b.t.Errorf("Container %s (%s, %v) is still around after %v", c.ID, c.Image, c.Names, timeoutAfter)
+			}
+			b.t.FailNow()
+		}
+	}
+}
+
+// WaitForNewContainer waits for a container created after the before snapshot (see Containers) to show up, and returns it
+func (b *Bzk) WaitForNewContainer(before map[string]docker.APIContainers, timeoutAfter time.Duration) docker.APIContainers {
+	giveUp := time.After(timeoutAfter)
+
+	for {
+		select {
+		case <-time.After(500 * time.Millisecond):
+			if containers := newContainers(before, b.Containers()); len(containers) > 0 {
+				b.t.Logf("Container %s (%s) was started", containers[0].ID, containers[0].Image)
+				return containers[0]
+			}
+
+		case <-giveUp:
+			b.t.Fatalf("No new container was started after %v", timeoutAfter)
+		}
+	}
+}
+
+func newContainers(before, after map[string]docker.APIContainers) []docker.APIContainers {
+	var res []docker.APIContainers
+	for id, c := range after {
+		if _, found := before[id]; !found {
+			res = append(res, c)
+		}
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package e2e
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PauseMongo freezes the mongodb process without closing its sockets: the server's open connections stay up,
+// but its queries hang instead of failing right away as they would if mongo was stopped.
+// TestMongoPaused relies on this to check the API times out on a stuck database rather than hanging
+func (b *Bzk) PauseMongo() {
+	b.t.Logf("Pausing the mongodb instance")
+	if err := b.docker.PauseContainer(b.mongoContainer.ID()); err != nil {
+		b.t.Fatalf("Failed to pause the mongo container: %v", err)
+	}
+}
+
+func (b *Bzk) UnpauseMongo() {
+	b.t.Logf("Unpausing the mongodb instance")
+	if err := b.docker.UnpauseContainer(b.mongoContainer.ID()); err != nil {
+		b.t.Fatalf("Failed to unpause the mongo container: %v", err)
+	}
+}
+
+// RestartMongo restarts the mongo container, dropping all the server connections
+func (b *Bzk) RestartMongo() {
+	b.t.Logf("Restarting the mongodb instance")
+	if err := b.docker.RestartContainer(b.mongoContainer.ID(), 10); err != nil {
+		b.t.Fatalf("Failed to restart the mongo container: %v", err)
+	}
+}
+
+// ProxyMongo routes the server's mongo traffic through a TCP proxy which can then be used to degrade the connection.
+// The server container is recreated to be pointed at the proxy, so this should be called before starting any job
+func (b *Bzk) ProxyMongo() *TCPProxy {
+	mongoPort := b.getHostPort(b.mongoContainer, "27017/tcp")
+	proxy := newTCPProxy(b.t, fmt.Sprintf("%s:%s", serverHost, mongoPort))
+	b.proxies = append(b.proxies, proxy)
+
+	// these override the ones set by the container link
+	b.serverEnv["MONGO_PORT_27017_TCP_ADDR"] = serverHost
+	b.serverEnv["MONGO_PORT_27017_TCP_PORT"] = strconv.Itoa(proxy.Port())
+	b.recreateServer()
+
+	return proxy
+}
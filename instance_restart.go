@@ -0,0 +1,52 @@
+package e2e
+
+import (
+	dockercmd "github.com/bywan/go-dockercommand"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// RestartServer gracefully stops the server container and starts it again, with the same mongo and bazooka home.
+// It returns once the API is reachable again
+func (b *Bzk) RestartServer() {
+	b.t.Logf("Restarting the bazooka server")
+	if err := b.docker.RestartContainer(b.serverContainer.ID(), 10); err != nil {
+		b.t.Fatalf("Failed to restart the server container: %v", err)
+	}
+	b.connect()
+	b.t.Logf("Restarted the bazooka server")
+}
+
+// KillServer kills the server container, simulating a crash, and starts it again with the same mongo and bazooka home.
+// It returns once the API is reachable again
+func (b *Bzk) KillServer() {
+	b.t.Logf("Killing the bazooka server")
+	if err := b.docker.KillContainer(docker.KillContainerOptions{ID: b.serverContainer.ID()}); err != nil {
+		b.t.Fatalf("Failed to kill the server container: %v", err)
+	}
+	if err := b.docker.StartContainer(b.serverContainer.ID(), nil); err != nil {
+		b.t.Fatalf("Failed to start the server container again: %v", err)
+	}
+	b.connect()
+	b.t.Logf("Started the bazooka server again")
+}
+
+// recreateServer replaces the server container by a new one, to take configuration changes into account
+func (b *Bzk) recreateServer() {
+	b.t.Logf("Recreating the server container")
+	if err := b.serverContainer.Remove(&dockercmd.RemoveOptions{
+		Force:         true,
+		RemoveVolumes: true,
+	}); err != nil {
+		b.t.Fatalf("Error while removing the server container: %v", err)
+	}
+	b.startServer()
+	b.connect()
+}
+
+// UpgradeServer replaces the server container by one running the given tag of the bazooka/server image,
+// using the same mongo and bazooka home
+func (b *Bzk) UpgradeServer(tag string) {
+	b.t.Logf("Upgrading the bazooka server from %s to %s", b.tag, tag)
+	b.tag = tag
+	b.recreateServer()
+}
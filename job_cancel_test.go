@@ -0,0 +1,190 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+)
+
+const (
+	sleeperStarted = "sleeping for"
+	sleeperDone    = "woke up"
+)
+
+func TestCancelJobMidVariant(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("cancel-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	before := bzk.Containers()
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	bzk.WaitForJobLog(job.ID, sleeperStarted, 60*time.Second)
+
+	res := bzk.CancelJob(job.ID)
+	require.Equal(t, http.StatusAccepted, res.Status, "job cancellation failed: %s", res.Body)
+
+	jobStatus := bzk.WaitForJob(job.ID, 30*time.Second)
+	require.Equal(t, jobCancelled, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Equal(t, 1, len(variants), "Should have exactly one variant")
+	require.Equal(t, jobCancelled, variants[0].Status)
+
+	bzk.WaitForContainersGone(before, 30*time.Second)
+
+	log := bzk.JobLog(job.ID)
+	require.Contains(t, log, sleeperStarted)
+	require.NotContains(t, log, sleeperDone, "the build should have been interrupted")
+
+	// the log is complete and stays so
+	time.Sleep(2 * time.Second)
+	require.Equal(t, log, bzk.JobLog(job.ID), "nothing should be logged after the job was cancelled")
+}
+
+func TestCancelJobBeforeCheckout(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("cancel-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	before := bzk.Containers()
+
+	// the checkout hangs until the git server is unpaused
+	repo.PauseServer()
+	defer repo.UnpauseServer()
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	res := bzk.CancelJob(job.ID)
+	require.Equal(t, http.StatusAccepted, res.Status, "job cancellation failed: %s", res.Body)
+
+	jobStatus := bzk.WaitForJob(job.ID, 30*time.Second)
+	require.Equal(t, jobCancelled, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Empty(t, variants, "no variant should have been started")
+
+	bzk.WaitForContainersGone(before, 30*time.Second)
+
+	require.NotContains(t, bzk.JobLog(job.ID), sleeperStarted, "the build should never have started")
+}
+
+func TestCancelFinishedJob(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("cancel-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", []string{"SLEEP=1"})
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	res := bzk.CancelJob(job.ID)
+	require.Equal(t, http.StatusConflict, res.Status, "cancelling a finished job should be rejected")
+
+	j, err := bzk.Api.Job.Get(job.ID)
+	require.NoError(t, err, "error while getting the job")
+	require.Equal(t, lib.JOB_SUCCESS, j.Status, "a finished job status should not change")
+}
+
+func TestConcurrentJobsOnSameProject(t *testing.T) {
+	recordCompat(t)
+	const jobCount = 3
+
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("concurrent-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	before := bzk.Containers()
+
+	type started struct {
+		job *lib.Job
+		err error
+	}
+	results := make(chan started, jobCount)
+	for i := 0; i < jobCount; i++ {
+		go func() {
+			job, err := bzk.Api.Project.StartJob(proj.ID, "master", []string{"SLEEP=5"})
+			results <- started{job, err}
+		}()
+	}
+
+	var jobs []*lib.Job
+	for i := 0; i < jobCount; i++ {
+		r := <-results
+		require.NoError(t, r.err, "job creation failed")
+		t.Logf("Started job: %v", r.job)
+		jobs = append(jobs, r.job)
+	}
+
+	ids := map[string]bool{}
+	var numbers []int
+	for _, job := range jobs {
+		jobStatus := bzk.WaitForJob(job.ID, 120*time.Second)
+		require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+		j, err := bzk.Api.Job.Get(job.ID)
+		require.NoError(t, err, "error while getting the job")
+		ids[j.ID] = true
+		numbers = append(numbers, j.Number)
+
+		require.Contains(t, bzk.JobLog(job.ID), sleeperDone, "the job log should be complete")
+	}
+
+	require.Equal(t, jobCount, len(ids), "every job should have its own id")
+	sort.Ints(numbers)
+	for i, n := range numbers {
+		require.Equal(t, i+1, n, "job numbers should be sequential, got %v", numbers)
+	}
+
+	bzk.WaitForContainersGone(before, 30*time.Second)
+}
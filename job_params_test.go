@@ -5,11 +5,13 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"fmt"
 	"testing"
 	"time"
 )
 
 func TestJobParameters(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
@@ -32,11 +34,12 @@ func TestJobParameters(t *testing.T) {
 }
 
 func TestJobParametersOverrideEnv(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
 	repo := bzk.NewRepository()
-	repo.ImportDir("data/params-override-project")
+	repo.ImportFixture("data/params-project", "data/overlays/params-env")
 	repo.GitAddAll()
 	repo.GitCommit("Point of inception")
 
@@ -52,3 +55,102 @@ func TestJobParametersOverrideEnv(t *testing.T) {
 
 	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
 }
+
+func TestJobParametersEdgeValues(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	params := map[string]string{
+		"FIRST":   "1",
+		"SECOND":  "2",
+		"EQUALS":  "a=b=c",
+		"SPACES":  "  hello   world  ",
+		"QUOTES":  `"double" and 'single'`,
+		"NEWLINE": "line 1\nline 2",
+		"UNICODE": "Ünïcödé ✓ 日本語",
+		"SHELL":   "$HOME `id` $(id) ; | &",
+		"EMPTY":   "",
+	}
+
+	var list []string
+	for k, v := range params {
+		list = append(list, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name:     "probe-proj",
+		Base:     "data/go-project",
+		Overlays: []string{probeOverlay},
+	}, list)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Equal(t, 1, len(variants), "Should have exactly one variant")
+
+	probe := bzk.VariantProbe(variants[0].ID)
+	for k, v := range params {
+		require.True(t, probe.HasEnv(k), "the parameter %s should be in the build env", k)
+		require.True(t, probe.EnvIs(k, v), "the parameter %s should be passed as is", k)
+	}
+}
+
+func TestJobParametersMalformed(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proj, err := bzk.Api.Project.Create("param-proj", "git", "nothing")
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	for _, params := range [][]string{
+		{"NOEQUALS"},
+		{"VALID=1", "NOEQUALS"},
+	} {
+		_, err := bzk.Api.Project.StartJob(proj.ID, "master", params)
+		require.Error(t, err, "starting a job with the parameters %q should fail", params)
+	}
+
+	jobs, err := bzk.Api.Project.Jobs(proj.ID)
+	require.NoError(t, err, "error while listing the project jobs")
+	require.Empty(t, jobs, "no job should have been created")
+}
+
+func TestJobParametersOverrideMatrix(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name:     "probe-proj",
+		Base:     "data/go-project",
+		Overlays: []string{probeOverlay},
+		Config: func(*lib.Project) *BzkConfig {
+			return probeConfig([]Env{
+				EnvVar("PARAM", "1"),
+				EnvVar("PARAM", "2"),
+				EnvVar("OTHER", "a"),
+				EnvVar("OTHER", "b"),
+			}, nil)
+		},
+	}, []string{"PARAM=42"})
+
+	jobStatus := bzk.WaitForJob(job.ID, 120*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Equal(t, 4, len(variants), "the parameter should not change the matrix")
+
+	others := map[string]bool{}
+	for _, v := range variants {
+		probe := bzk.VariantProbe(v.ID)
+		require.True(t, probe.EnvIs("PARAM", "42"), "the parameter should override the matrix variable in every variant")
+		others[probe.EnvAmong("OTHER", "a", "b")] = true
+	}
+	require.Equal(t, map[string]bool{"a": true, "b": true}, others, "the other matrix variable should be left alone")
+}
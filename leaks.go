@@ -0,0 +1,121 @@
+package e2e
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var (
+	// when set, leaked docker resources fail the test instead of just being reported
+	strictLeaks bool
+)
+
+const (
+	// resources are sometimes removed asynchronously, so the leaks are only reported if they outlive this delay
+	leakGracePeriod = 10 * time.Second
+)
+
+// dockerSnapshot lists the docker resources present at some point in time, indexed by their ids
+type dockerSnapshot struct {
+	containers map[string]docker.APIContainers
+	volumes    map[string]docker.Volume
+	networks   map[string]docker.Network
+	images     map[string]docker.APIImages
+}
+
+// snapshotDocker lists the containers, volumes, networks and dangling images of the docker daemon.
+// Volumes and networks are skipped when the daemon doesn't support listing them
+func (b *Bzk) snapshotDocker() *dockerSnapshot {
+	s := &dockerSnapshot{
+		containers: b.Containers(),
+		volumes:    map[string]docker.Volume{},
+		networks:   map[string]docker.Network{},
+		images:     map[string]docker.APIImages{},
+	}
+
+	if volumes, err := b.docker.ListVolumes(docker.ListVolumesOptions{}); err == nil {
+		for _, v := range volumes {
+			s.volumes[v.Name] = v
+		}
+	} else {
+		b.t.Logf("Cannot list the docker volumes: %v", err)
+	}
+
+	if networks, err := b.docker.ListNetworks(); err == nil {
+		for _, n := range networks {
+			s.networks[n.ID] = n
+		}
+	} else {
+		b.t.Logf("Cannot list the docker networks: %v", err)
+	}
+
+	images, err := b.docker.ListImages(docker.ListImagesOptions{
+		Filters: map[string][]string{"dangling": {"true"}},
+	})
+	if err != nil {
+		b.t.Fatalf("Failed to list the dangling docker images: %v", err)
+	}
+	for _, i := range images {
+		s.images[i.ID] = i
+	}
+
+	return s
+}
+
+// leaks lists the resources present in s but not in before, one description per resource
+func (s *dockerSnapshot) leaks(before *dockerSnapshot) []string {
+	var res []string
+	for id, c := range s.containers {
+		if _, found := before.containers[id]; !found {
+			res = append(res, fmtLeak("container", id, c.Image, c.Labels))
+		}
+	}
+	for name, v := range s.volumes {
+		if _, found := before.volumes[name]; !found {
+			res = append(res, fmtLeak("volume", name, v.Driver, v.Labels))
+		}
+	}
+	for id, n := range s.networks {
+		if _, found := before.networks[id]; !found {
+			res = append(res, fmtLeak("network", n.Name, n.Driver, n.Labels))
+		}
+	}
+	for id, i := range s.images {
+		if _, found := before.images[id]; !found {
+			res = append(res, fmtLeak("dangling image", id, "<none>", i.Labels))
+		}
+	}
+	return res
+}
+
+func fmtLeak(kind, id, image string, labels map[string]string) string {
+	var pairs []string
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return fmt.Sprintf("%s %s (%s) labels: %v", kind, id, image, pairs)
+}
+
+// checkLeaks reports every docker resource which appeared since the before snapshot and is still there after the grace period.
+// The test fails on leaks in strict mode
+func (b *Bzk) checkLeaks(before *dockerSnapshot) {
+	giveUp := time.Now().Add(leakGracePeriod)
+
+	leaks := b.snapshotDocker().leaks(before)
+	for len(leaks) > 0 && time.Now().Before(giveUp) {
+		time.Sleep(500 * time.Millisecond)
+		leaks = b.snapshotDocker().leaks(before)
+	}
+
+	report := b.t.Logf
+	if strictLeaks {
+		report = b.t.Errorf
+	}
+	for _, leak := range leaks {
+		report("Leaked docker resource: %s", leak)
+	}
+}
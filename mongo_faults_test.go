@@ -0,0 +1,141 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestMongoPaused(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proj, err := bzk.Api.Project.Create("mongo-proj", "git", "git://example.com/repo.git")
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	bzk.PauseMongo()
+
+	// the API should fail fast instead of hanging
+	res, err := bzk.TryRawRequest("GET", "/project", nil)
+	bzk.UnpauseMongo()
+	require.NoError(t, err, "the API should answer while the database is unavailable")
+	require.True(t, res.Status >= 500, "the API should report an error while the database is unavailable, got %d", res.Status)
+
+	bzk.WaitForAPI(30 * time.Second)
+
+	projects, err := bzk.Api.Project.List()
+	require.NoError(t, err, "error while listing projects")
+	require.Equal(t, 1, len(projects), "the project should still be there")
+}
+
+func TestMongoRestartDuringJob(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("mongo-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", []string{"SLEEP=15"})
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	bzk.WaitForJobLog(job.ID, sleeperStarted, 60*time.Second)
+
+	bzk.RestartMongo()
+	bzk.WaitForAPI(30 * time.Second)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus, "the job should survive a database restart")
+	require.Contains(t, bzk.JobLog(job.ID), sleeperDone, "the job log should be complete")
+}
+
+func TestMongoLatency(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proxy := bzk.ProxyMongo()
+	proxy.SetLatency(200 * time.Millisecond)
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("mongo-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 120*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
+
+func TestMongoLowBandwidth(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proxy := bzk.ProxyMongo()
+	proxy.SetBandwidth(16 * 1024)
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("mongo-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 120*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
+
+func TestMongoDroppedConnections(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proxy := bzk.ProxyMongo()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("mongo-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", []string{"SLEEP=15"})
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	bzk.WaitForJobLog(job.ID, sleeperStarted, 60*time.Second)
+
+	proxy.DropConnections()
+	bzk.WaitForAPI(30 * time.Second)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus, "the job should survive dropped database connections")
+}
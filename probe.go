@@ -0,0 +1,107 @@
+package e2e
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// the fixture overlay running the probe script as the build script
+	probeOverlay = "data/overlays/probe"
+	probeScript  = "bzk-probe.sh"
+	probeMarker  = "BZK-PROBE "
+)
+
+// Probe is what the probe script saw of the build container
+type Probe struct {
+	Cwd string `json:"cwd"`
+	// the sha256 of the build environment variables values, so that secrets don't end up in the build log
+	EnvHashes map[string]string `json:"env"`
+	Files     []string          `json:"files"`
+	Tools     map[string]string `json:"tools"`
+}
+
+// HasEnv tells whether the variable is set in the build environment, even to an empty value
+func (p *Probe) HasEnv(name string) bool {
+	_, found := p.EnvHashes[name]
+	return found
+}
+
+// EnvIs tells whether the variable is set to value in the build environment
+func (p *Probe) EnvIs(name, value string) bool {
+	hash, found := p.EnvHashes[name]
+	return found && hash == sha256Hex(value)
+}
+
+// EnvAmong returns the candidate the variable is set to in the build environment,
+// or a placeholder saying it's unset or set to something else
+func (p *Probe) EnvAmong(name string, candidates ...string) string {
+	if !p.HasEnv(name) {
+		return "<unset>"
+	}
+	for _, c := range candidates {
+		if p.EnvIs(name, c) {
+			return c
+		}
+	}
+	return "<other value>"
+}
+
+func (p *Probe) HasFile(path string) bool {
+	for _, f := range p.Files {
+		if f == path {
+			return true
+		}
+	}
+	return false
+}
+
+// probeConfig is a go build config running the probe as its script, for fixtures including the probe overlay
+func probeConfig(env []Env, matrix *Matrix) *BzkConfig {
+	return &BzkConfig{
+		Language: "golang",
+		Go:       []string{"1.4"},
+		Env:      env,
+		Matrix:   matrix,
+		Script:   []string{"./" + probeScript},
+	}
+}
+
+// ParseProbe extracts the probe output from a build log
+func ParseProbe(log string) (*Probe, error) {
+	for _, line := range strings.Split(log, "\n") {
+		i := strings.Index(line, probeMarker)
+		if i == -1 {
+			continue
+		}
+		payload := strings.TrimSpace(line[i+len(probeMarker):])
+		if !strings.HasPrefix(payload, "{") {
+			// the script line itself, echoed before running
+			continue
+		}
+
+		var p Probe
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return nil, fmt.Errorf("invalid probe output %q: %v", payload, err)
+		}
+		return &p, nil
+	}
+	return nil, fmt.Errorf("no probe output found")
+}
+
+// VariantProbe returns the probe output of a variant
+func (b *Bzk) VariantProbe(variantID string) *Probe {
+	p, err := ParseProbe(b.VariantLog(variantID))
+	if err != nil {
+		b.t.Fatalf("Error while reading the probe output of variant %s: %v", variantID, err)
+	}
+	return p
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
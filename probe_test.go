@@ -0,0 +1,44 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestProbeBuildContainer(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportFixture("data/go-project", probeOverlay)
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("probe-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", []string{"PARAM=42"})
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Equal(t, 1, len(variants), "Should have exactly one variant")
+
+	probe := bzk.VariantProbe(variants[0].ID)
+	t.Logf("Probe: %+v", probe)
+
+	require.True(t, probe.EnvIs("PARAM", "42"), "the job parameter should be in the build env")
+	require.True(t, probe.HasFile("./main.go"), "the sources should be in the working directory")
+	require.True(t, probe.HasFile("./"+probeScript), "the sources should be in the working directory")
+	require.Contains(t, probe.Tools["go"], "go1.4", "the build should use the requested go version")
+}
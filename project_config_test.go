@@ -1,9 +1,15 @@
 package e2e
 
 import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
 	"github.com/stretchr/testify/require"
 
+	"fmt"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -13,6 +19,7 @@ const (
 )
 
 func TestProjectConfig(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
@@ -48,3 +55,178 @@ func getProjectConfigKey(bzk *Bzk, projectID, key string) string {
 	require.NoError(bzk.t, err, "error while getting a project config key")
 	return cfg[key]
 }
+
+func TestProjectConfigEdgeValues(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proj, err := bzk.Api.Project.Create("config-proj", "git", "nothing")
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	entries := map[string]string{
+		"a.b.c.d.e":       "deeply nested",
+		"unicode":         "Ünïcödé ✓ 日本語 🚀",
+		"quotes":          `"double" and 'single'`,
+		"special":         `$HOME & <tag> %s \n ; | {{.Secure}}`,
+		"multiline":       "line 1\nline 2\n",
+		"spaces":          "  leading and trailing  ",
+		"url":             "http://user:p@ss@example.com/path?q=1&r=2#frag",
+		"key-with_dashes": "dashes",
+	}
+
+	for k, v := range entries {
+		err = bzk.Api.Project.Config.SetKey(proj.ID, k, v)
+		require.NoError(t, err, "error while setting the project config key %s", k)
+	}
+
+	cfg, err := bzk.Api.Project.Config.Get(proj.ID)
+	require.NoError(t, err, "error while getting the project config")
+	for k, v := range entries {
+		require.Equal(t, v, cfg[k], "project config mismatch for key %s", k)
+	}
+
+	// unsetting a nested key leaves its siblings alone
+	err = bzk.Api.Project.Config.SetKey(proj.ID, "a.b.c.d.f", "sibling")
+	require.NoError(t, err, "error while setting a project config key")
+	err = bzk.Api.Project.Config.UnsetKey(proj.ID, "a.b.c.d.e")
+	require.NoError(t, err, "error while unsetting a project config key")
+	require.Empty(t, getProjectConfigKey(bzk, proj.ID, "a.b.c.d.e"), "the key should have been deleted")
+	require.Equal(t, "sibling", getProjectConfigKey(bzk, proj.ID, "a.b.c.d.f"), "the sibling key should be left alone")
+}
+
+func TestProjectConfigBulkAndConcurrentUpdates(t *testing.T) {
+	recordCompat(t)
+	const (
+		writers = 10
+		keys    = 20
+	)
+
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proj, err := bzk.Api.Project.Create("config-proj", "git", "nothing")
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	// every writer sets its own keys, and all of them fight over a shared one
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*(keys+1))
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				errs <- bzk.Api.Project.Config.SetKey(proj.ID, fmt.Sprintf("writer%d.key%d", w, k), fmt.Sprintf("value-%d-%d", w, k))
+			}
+			errs <- bzk.Api.Project.Config.SetKey(proj.ID, "shared", fmt.Sprintf("writer-%d", w))
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err, "error while setting a project config key")
+	}
+
+	cfg, err := bzk.Api.Project.Config.Get(proj.ID)
+	require.NoError(t, err, "error while getting the project config")
+	require.Equal(t, writers*keys+1, len(cfg), "no update should have been lost")
+	for w := 0; w < writers; w++ {
+		for k := 0; k < keys; k++ {
+			require.Equal(t, fmt.Sprintf("value-%d-%d", w, k), cfg[fmt.Sprintf("writer%d.key%d", w, k)])
+		}
+	}
+	require.Regexp(t, "^writer-[0-9]+$", cfg["shared"], "the shared key should hold one of the written values")
+}
+
+func TestProjectConfigSurvivesRestart(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proj, err := bzk.Api.Project.Create("config-proj", "git", "nothing")
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	err = bzk.Api.Project.Config.SetKey(proj.ID, projectConfigKey, projectConfigValue)
+	require.NoError(t, err, "error while setting a project config key")
+
+	bzk.RestartServer()
+
+	require.Equal(t, projectConfigValue, getProjectConfigKey(bzk, proj.ID, projectConfigKey), "project config mismatch after a restart")
+}
+
+func TestProjectConfigEnablesCommitStatus(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	codeHost := bzk.NewFakeCodeHost()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("config-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	// without the code host config, nothing is reported
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	sha := repo.GitRevision("master")
+	require.Empty(t, codeHost.Statuses(sha), "no status should be reported without the code host config")
+
+	// once configured, the next job reports its status
+	codeHost.Configure(bzk, proj.ID)
+
+	job, err = bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	jobStatus = bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	codeHost.WaitForFinalStatus(sha, 10*time.Second)
+	require.Equal(t, []string{"pending", "success"}, codeHost.States(sha), "unexpected commit statuses sequence")
+}
+
+func TestProjectConfigHookSecret(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("config-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	err = bzk.Api.Project.Config.SetKey(proj.ID, githubSecretConfigKey, hookSecret)
+	require.NoError(t, err, "error while setting the github secret")
+
+	event := repo.PushEvent("master")
+
+	// unsigned and badly signed hooks are rejected once a secret is configured
+	require.Equal(t, http.StatusUnauthorized, bzk.NotifyGithubPush(proj.ID, event, ""), "an unsigned hook should be rejected")
+	require.Equal(t, http.StatusUnauthorized, bzk.NotifyGithubPush(proj.ID, event, "not the secret"), "a badly signed hook should be rejected")
+
+	jobs, err := bzk.Api.Project.Jobs(proj.ID)
+	require.NoError(t, err, "error while listing the project jobs")
+	require.Empty(t, jobs, "rejected hooks should not start jobs")
+
+	// unsetting the secret accepts unsigned hooks again
+	err = bzk.Api.Project.Config.UnsetKey(proj.ID, githubSecretConfigKey)
+	require.NoError(t, err, "error while unsetting the github secret")
+
+	status := bzk.NotifyGithubPush(proj.ID, event, "")
+	require.True(t, status >= 200 && status < 300, "the github hook was rejected with status %d", status)
+
+	requirePushedJob(t, bzk, proj.ID, event)
+}
@@ -0,0 +1,153 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestProjectLifecycle(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	proj1, err := bzk.Api.Project.Create("lifecycle-proj-1", "git", "git://example.com/1.git")
+	require.NoError(t, err, "error while creating a project")
+	proj2, err := bzk.Api.Project.Create("lifecycle-proj-2", "git", "git://example.com/2.git")
+	require.NoError(t, err, "error while creating a project")
+
+	// list
+	projects, err := bzk.Api.Project.List()
+	require.NoError(t, err, "error while listing projects")
+	require.Equal(t, 2, len(projects), "should have exactly 2 projects")
+
+	// get
+	fetched, err := bzk.Api.Project.Get(proj1.ID)
+	require.NoError(t, err, "error while getting a project")
+	require.Equal(t, "lifecycle-proj-1", fetched.Name)
+	require.Equal(t, "git", fetched.ScmType)
+	require.Equal(t, "git://example.com/1.git", fetched.ScmURI)
+
+	// update
+	res := bzk.UpdateProject(proj1.ID, map[string]string{
+		"name":    "lifecycle-proj-renamed",
+		"scm_uri": "git://example.com/renamed.git",
+	})
+	require.Equal(t, http.StatusOK, res.Status, "project update failed: %s", res.Body)
+
+	fetched, err = bzk.Api.Project.Get(proj1.ID)
+	require.NoError(t, err, "error while getting a project")
+	require.Equal(t, "lifecycle-proj-renamed", fetched.Name)
+	require.Equal(t, "git://example.com/renamed.git", fetched.ScmURI)
+
+	// delete
+	res = bzk.DeleteProject(proj1.ID)
+	require.Equal(t, http.StatusNoContent, res.Status, "project deletion failed: %s", res.Body)
+
+	res = bzk.RawRequest("GET", fmt.Sprintf("/project/%s", proj1.ID), nil)
+	require.Equal(t, http.StatusNotFound, res.Status, "a deleted project should not be found")
+
+	projects, err = bzk.Api.Project.List()
+	require.NoError(t, err, "error while listing projects")
+	require.Equal(t, 1, len(projects), "should have exactly 1 project left")
+	require.Equal(t, proj2.ID, projects[0].ID)
+
+	res = bzk.DeleteProject(proj1.ID)
+	require.Equal(t, http.StatusNotFound, res.Status, "deleting a project twice should fail")
+}
+
+func TestProjectDuplicateName(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	res := bzk.CreateProject("dup-proj", "git", "git://example.com/1.git")
+	require.Equal(t, http.StatusCreated, res.Status, "project creation failed: %s", res.Body)
+
+	res = bzk.CreateProject("dup-proj", "git", "git://example.com/2.git")
+	require.Equal(t, http.StatusConflict, res.Status, "duplicate project names should be rejected")
+
+	proj2, err := bzk.Api.Project.Create("dup-proj-2", "git", "git://example.com/2.git")
+	require.NoError(t, err, "error while creating a project")
+
+	res = bzk.UpdateProject(proj2.ID, map[string]string{"name": "dup-proj"})
+	require.Equal(t, http.StatusConflict, res.Status, "renaming a project to an existing name should be rejected")
+}
+
+func TestProjectInvalidCreation(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	cases := []struct {
+		desc, name, scmType, scmURI string
+	}{
+		{"empty name", "", "git", "git://example.com/repo.git"},
+		{"empty scm type", "invalid-proj", "", "git://example.com/repo.git"},
+		{"unsupported scm type", "invalid-proj", "svn", "git://example.com/repo.git"},
+		{"empty url", "invalid-proj", "git", ""},
+		{"malformed url", "invalid-proj", "git", "://not a url"},
+	}
+
+	for _, c := range cases {
+		res := bzk.CreateProject(c.name, c.scmType, c.scmURI)
+		require.Equal(t, http.StatusBadRequest, res.Status, "%s: the project creation should have been rejected", c.desc)
+	}
+
+	projects, err := bzk.Api.Project.List()
+	require.NoError(t, err, "error while listing projects")
+	require.Empty(t, projects, "no project should have been created")
+}
+
+func TestDeleteProjectWithRunningJob(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.WriteConfig(&BzkConfig{
+		Language: "golang",
+		Go:       []string{"1.4"},
+		Script:   []string{"sleep 20"},
+	})
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("running-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	err = bzk.Api.Project.Config.SetKey(proj.ID, projectConfigKey, projectConfigValue)
+	require.NoError(t, err, "error while setting a project config key")
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	// a project can't be deleted while one of its jobs is running
+	res := bzk.DeleteProject(proj.ID)
+	require.Equal(t, http.StatusConflict, res.Status, "deleting a project with a running job should be rejected")
+
+	jobStatus := bzk.WaitForJob(job.ID, 90*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	res = bzk.DeleteProject(proj.ID)
+	require.Equal(t, http.StatusNoContent, res.Status, "project deletion failed: %s", res.Body)
+
+	// its jobs and config are gone too
+	res = bzk.RawRequest("GET", fmt.Sprintf("/job/%s", job.ID), nil)
+	require.Equal(t, http.StatusNotFound, res.Status, "the jobs of a deleted project should be deleted")
+
+	res = bzk.RawRequest("GET", fmt.Sprintf("/project/%s/config", proj.ID), nil)
+	require.Equal(t, http.StatusNotFound, res.Status, "the config of a deleted project should be deleted")
+
+	jobs, err := bzk.Api.Job.List()
+	require.NoError(t, err, "error while listing jobs")
+	require.Empty(t, jobs, "the jobs of a deleted project should not be listed")
+}
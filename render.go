@@ -0,0 +1,140 @@
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// templates are rendered to the same path minus this suffix
+	renderSuffix = ".tmpl"
+	// files listed in this manifest (one path per line, relative to the repository root) are rendered in place
+	renderManifest = ".bzk-render"
+)
+
+var renderFuncs = template.FuncMap{
+	"env": os.Getenv,
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"sha": sha256Hex,
+	"yaml": func(v interface{}) (string, error) {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(b), "\n"), nil
+	},
+}
+
+// Render renders the given file in place, using model as the template data
+func (r *Repository) Render(file string, model map[string]interface{}) {
+	r.render(file, file, model)
+}
+
+// RenderAll renders every file in the repository either ending with the .tmpl suffix
+// or listed in the render manifest.
+// Templates are written without their suffix and removed, as is the manifest itself
+func (r *Repository) RenderAll(model map[string]interface{}) {
+	manifest := filepath.Join(r.location, renderManifest)
+	if _, err := os.Stat(manifest); err == nil {
+		for _, file := range r.readRenderManifest(manifest) {
+			r.render(file, file, model)
+		}
+		if err := os.Remove(manifest); err != nil {
+			r.t.Fatalf("Error while removing the render manifest: %v", err)
+		}
+	}
+
+	if err := filepath.Walk(r.location, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.IsDir() && info.Name() == ".git":
+			return filepath.SkipDir
+		case info.IsDir() || !strings.HasSuffix(path, renderSuffix):
+			return nil
+		}
+
+		src, err := filepath.Rel(r.location, path)
+		if err != nil {
+			return err
+		}
+		r.render(src, strings.TrimSuffix(src, renderSuffix), model)
+		return os.Remove(path)
+	}); err != nil {
+		r.t.Fatalf("Error while rendering the repository %d templates: %v", r.index, err)
+	}
+}
+
+func (r *Repository) readRenderManifest(manifest string) []string {
+	f, err := os.Open(manifest)
+	if err != nil {
+		r.t.Fatalf("Error while opening the render manifest: %v", err)
+	}
+	defer f.Close()
+
+	var files []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); len(line) > 0 && !strings.HasPrefix(line, "#") {
+			files = append(files, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		r.t.Fatalf("Error while reading the render manifest: %v", err)
+	}
+	return files
+}
+
+// render executes the template src in strict mode (any missing key is an error) and writes the result to dst.
+// Both paths are relative to the repository root
+func (r *Repository) render(src, dst string, model map[string]interface{}) {
+	if err := renderFile(r.location, src, dst, model); err != nil {
+		r.t.Fatalf("%v", err)
+	}
+	r.t.Logf("Rendered %s into %s", src, dst)
+}
+
+// renderFile does the actual rendering of render, with src and dst relative to root
+func renderFile(root, src, dst string, model map[string]interface{}) error {
+	b, err := ioutil.ReadFile(filepath.Join(root, src))
+	if err != nil {
+		return fmt.Errorf("Error while reading %s: %v", src, err)
+	}
+
+	if bytes.IndexByte(b, 0) != -1 {
+		return fmt.Errorf("Refusing to render %s: it looks like a binary file", src)
+	}
+
+	tpl, err := template.New(src).Option("missingkey=error").Funcs(renderFuncs).Parse(string(b))
+	if err != nil {
+		return fmt.Errorf("Error while parsing template %s: %v", src, err)
+	}
+
+	var out bytes.Buffer
+	if err := tpl.Execute(&out, model); err != nil {
+		return fmt.Errorf("Error while executing the template %s: %v", src, err)
+	}
+
+	info, err := os.Stat(filepath.Join(root, src))
+	if err != nil {
+		return fmt.Errorf("Error while reading %s: %v", src, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, dst), out.Bytes(), info.Mode()); err != nil {
+		return fmt.Errorf("Error while writing %s: %v", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderedFixture(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/render-project")
+	repo.RenderAll(map[string]interface{}{
+		"Message": "Hello: bazooka #42",
+	})
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("render-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
+
+func TestRenderStrict(t *testing.T) {
+	recordCompat(t)
+	root, err := ioutil.TempDir(tempDir, "bazooka-render")
+	require.NoError(t, err, "error while creating a temp dir")
+	defer os.RemoveAll(root)
+
+	writeRenderTemplate(t, root, "ok.txt", "{{.Message}} {{sha .Message}}")
+	require.NoError(t, renderFile(root, "ok.txt", "ok.txt", map[string]interface{}{"Message": "bzk"}))
+	rendered, err := ioutil.ReadFile(filepath.Join(root, "ok.txt"))
+	require.NoError(t, err, "error while reading the rendered file")
+	require.Equal(t, "bzk faf04c150d4469bb3f1f93571b0b72181e3807c4e2d164a5808a4df1d7b4b804", string(rendered))
+
+	writeRenderTemplate(t, root, "missing.txt", "{{.Message}} {{.Missing}}")
+	err = renderFile(root, "missing.txt", "missing.txt", map[string]interface{}{"Message": "bzk"})
+	require.Error(t, err, "rendering a template referencing a missing key should fail")
+	require.Contains(t, err.Error(), "Missing")
+
+	writeRenderTemplate(t, root, "binary.bin", "{{.Message}}\x00\x01")
+	err = renderFile(root, "binary.bin", "binary.bin", map[string]interface{}{"Message": "bzk"})
+	require.Error(t, err, "rendering a binary file should fail")
+	require.Contains(t, err.Error(), "binary")
+}
+
+func writeRenderTemplate(t *testing.T, root, name, content string) {
+	if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write the template %s: %v", name, err)
+	}
+}
@@ -2,18 +2,18 @@ package e2e
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"strings"
 	"testing"
-	"text/template"
 
 	dockercmd "github.com/bywan/go-dockercommand"
+	docker "github.com/fsouza/go-dockerclient"
 )
 
 var (
@@ -26,7 +26,10 @@ type Repository struct {
 
 	location string
 
+	lastCommitMessage string
+
 	dockerClient *dockercmd.Docker
+	docker       *docker.Client
 	container    *dockercmd.Container
 	port         string
 }
@@ -37,7 +40,9 @@ func (b *Bzk) NewRepository() *Repository {
 
 	location := path.Join(tempDir, fmt.Sprintf("bazooka-repo-%d", index))
 
-	os.RemoveAll(location)
+	if err := removeAll(b.t, b.dockerClient, location); err != nil {
+		b.t.Fatalf("Failed to clean the temp dir for repository %d: %v", index, err)
+	}
 	if err := os.MkdirAll(location, 0755); err != nil {
 		b.t.Fatalf("Failed to allocate a temp dir for repository %d: %v", index, err)
 	}
@@ -48,6 +53,7 @@ func (b *Bzk) NewRepository() *Repository {
 		index:        index,
 		location:     location,
 		dockerClient: b.dockerClient,
+		docker:       b.docker,
 	}
 
 	repo.cmd("git", "init")
@@ -77,11 +83,6 @@ func (b *Bzk) NewRepository() *Repository {
 }
 
 func (r *Repository) teardown() {
-	r.t.Logf("Deleting the repository directory: %s", r.location)
-	if err := os.RemoveAll(r.location); err != nil {
-		r.t.Errorf("Error while deleting the repository directory: %v", err)
-	}
-
 	r.t.Logf("Removing the git server container")
 	if err := r.container.Remove(&dockercmd.RemoveOptions{
 		Force:         true,
@@ -89,18 +90,28 @@ func (r *Repository) teardown() {
 	}); err != nil {
 		r.t.Errorf("Error while removing the git server container: %v", err)
 	}
+
+	r.t.Logf("Deleting the repository directory: %s", r.location)
+	if err := removeAll(r.t, r.dockerClient, r.location); err != nil {
+		r.t.Errorf("Error while deleting the repository directory: %v", err)
+	}
 }
 
 func (r *Repository) CloneURL() string {
 	return fmt.Sprintf("git://%s:%s/", "boot2docker", r.port)
 }
 
+// ImportFile copies the file src into the repository as dst, preserving its permissions.
+// Symbolic links are recreated as is instead of being followed
 func (r *Repository) ImportFile(src, dst string) {
-	if err := copyFileContents(src, filepath.Join(r.location, dst)); err != nil {
+	if err := copyFile(src, filepath.Join(r.location, dst)); err != nil {
 		r.t.Fatalf("Error while copying file %s to the repository %d: %v", src, r.index, err)
 	}
 }
 
+// ImportDir copies the content of the src directory into the repository root.
+// Directories, including empty ones, are created with the same permissions as their source.
+// Note that git doesn't track empty directories, so these won't be part of any commit
 func (r *Repository) ImportDir(src string) {
 	if err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -111,10 +122,7 @@ func (r *Repository) ImportDir(src string) {
 		case path == src:
 			return nil
 		case info.IsDir():
-			dst := filepath.Join(r.location, strings.TrimPrefix(path, src))
-			if err := os.MkdirAll(dst, 0755); err != nil {
-				return err
-			}
+			return mkdirAs(filepath.Join(r.location, strings.TrimPrefix(path, src)), info)
 		default:
 			r.ImportFile(path, strings.TrimPrefix(path, src))
 		}
@@ -125,30 +133,6 @@ func (r *Repository) ImportDir(src string) {
 	}
 }
 
-func (r *Repository) Render(file string, model map[string]interface{}) {
-	fullPath := path.Join(r.location, file)
-
-	b, err := ioutil.ReadFile(fullPath)
-	if err != nil {
-		r.t.Fatalf("Error while reading %s: %v", file, err)
-	}
-
-	tpl, err := template.New("_").Parse(string(b))
-	if err != nil {
-		r.t.Fatalf("Error while parsing template %s: %v", file, err)
-	}
-
-	out, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		r.t.Fatalf("Error while opening %s for write: %v", file, err)
-	}
-	defer out.Close()
-
-	if err := tpl.Execute(out, model); err != nil {
-		r.t.Fatalf("Error while executing the template %s: %v", file, err)
-	}
-}
-
 func (r *Repository) cmd(cmd ...string) {
 	r.t.Logf("Executing command %v", cmd)
 	container, err := r.dockerClient.Run(&dockercmd.RunOptions{
@@ -182,6 +166,37 @@ func (r *Repository) cmd(cmd ...string) {
 	}
 }
 
+// exec runs the command in the git server container, for the commands which must go through its daemon
+func (r *Repository) exec(cmd ...string) {
+	r.t.Logf("Executing command %v in the git server container", cmd)
+	execution, err := r.docker.CreateExec(docker.CreateExecOptions{
+		Container:    r.container.ID(),
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		r.t.Fatalf("Failed to create the command %v: %v", cmd, err)
+	}
+
+	var out bytes.Buffer
+	if err := r.docker.StartExec(execution.ID, docker.StartExecOptions{
+		OutputStream: &out,
+		ErrorStream:  &out,
+	}); err != nil {
+		r.t.Fatalf("Failed to execute the command %v: %v", cmd, err)
+	}
+	r.t.Logf("[<exec>] %s", out.String())
+
+	inspect, err := r.docker.InspectExec(execution.ID)
+	if err != nil {
+		r.t.Fatalf("Failed to retrieve the exit code of command %v: %v", cmd, err)
+	}
+	if inspect.ExitCode != 0 {
+		r.t.Fatalf("Failed to execute the command %v: exit code %d", cmd, inspect.ExitCode)
+	}
+}
+
 func (r *Repository) ContainerLog(prefix string, container *dockercmd.Container) {
 	reader, writer := io.Pipe()
 	container.StreamLogs(writer)
@@ -197,6 +212,41 @@ func (r *Repository) ContainerLog(prefix string, container *dockercmd.Container)
 	}(reader)
 }
 
+// mkdirAs creates the directory dst, if needed, with the same permissions as the directory described by info
+func mkdirAs(dst string, info os.FileInfo) error {
+	if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	// MkdirAll is subject to the umask and leaves existing directories untouched
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
+// copyFile copies the file named src to the file named by dst, replacing it if it already exists.
+// The permission bits are preserved, and symbolic links are copied as links.
+func copyFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	}
+
+	if err := copyFileContents(src, dst); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
 // copyFileContents copies the contents of the file named src to the file named
 // by dst. The file will be created if it does not already exist. If the
 // destination file exists, all it's contents will be replaced by the contents
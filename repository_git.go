@@ -1,6 +1,13 @@
 package e2e
 
-import "fmt"
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 func (r *Repository) GitAddAll() {
 	r.cmd("git", "add", "-A")
@@ -8,4 +15,54 @@ func (r *Repository) GitAddAll() {
 
 func (r *Repository) GitCommit(msg string) {
 	r.cmd("git", "commit", "-m", fmt.Sprintf("\"%s\"", msg))
+	r.lastCommitMessage = msg
+}
+
+func (r *Repository) GitDeleteBranch(branch string) {
+	r.cmd("git", "update-ref", "-d", fmt.Sprintf("refs/heads/%s", branch))
+}
+
+// GitRevision returns the SHA the given branch points to.
+// The refs are read directly from the repository directory, loose refs first, then packed ones
+func (r *Repository) GitRevision(branch string) string {
+	ref := fmt.Sprintf("refs/heads/%s", branch)
+
+	b, err := ioutil.ReadFile(filepath.Join(r.location, ".git", ref))
+	if err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	if !os.IsNotExist(err) {
+		r.t.Fatalf("Error while reading the ref %s of repository %d: %v", ref, r.index, err)
+	}
+
+	f, err := os.Open(filepath.Join(r.location, ".git", "packed-refs"))
+	if err != nil {
+		r.t.Fatalf("Cannot resolve the ref %s of repository %d: %v", ref, r.index, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+	r.t.Fatalf("Cannot resolve the ref %s of repository %d", ref, r.index)
+	return ""
+}
+
+// GitPush pushes the current head to branch through the repository's git daemon,
+// which runs the post-receive hook the way a push from a developer would
+func (r *Repository) GitPush(branch string) {
+	r.exec("git", "push", "git://localhost/", fmt.Sprintf("HEAD:refs/heads/%s", branch))
+}
+
+func (r *Repository) GitCheckoutNewBranch(branch string) {
+	r.cmd("git", "checkout", "-b", branch)
+}
+
+// NotifyCommitsTo configures the repository hooks to notify url of every new commit or push,
+// using a github-style push payload
+func (r *Repository) NotifyCommitsTo(url string) {
+	r.cmd("git", "config", "bazooka.notifyurl", url)
 }
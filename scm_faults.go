@@ -0,0 +1,45 @@
+package e2e
+
+const (
+	deniedGitDir = ".git-denied"
+)
+
+// StopServer stops the git daemon serving this repository, paused or not.
+// Any fetch in progress or attempted afterwards fails with a connection error.
+func (r *Repository) StopServer() {
+	r.t.Logf("Stopping the git server for repository %d", r.index)
+	if err := r.docker.StopContainer(r.container.ID(), 5); err != nil {
+		r.t.Fatalf("Failed to stop the git server container for repository %d: %v", r.index, err)
+	}
+}
+
+// CorruptPack packs all the repository objects and overwrites a few bytes in the middle of
+// the resulting pack files, so that serving them fails mid-transfer.
+func (r *Repository) CorruptPack() {
+	r.t.Logf("Corrupting the pack files of repository %d", r.index)
+	r.cmd("sh", "-c", "git repack -a -d -q && git prune-packed && "+
+		"for p in .git/objects/pack/*.pack; do printf 'bazooka!' | dd of=$p bs=1 seek=32 conv=notrunc; done")
+}
+
+// DenyAccess moves the git directory out of the daemon's whitelisted path,
+// which makes it answer every request with an access denied error.
+func (r *Repository) DenyAccess() {
+	r.t.Logf("Denying access to repository %d", r.index)
+	r.cmd("mv", ".git", deniedGitDir)
+}
+
+// PauseServer freezes the git daemon serving this repository: connections are accepted but never answered,
+// until UnpauseServer is called
+func (r *Repository) PauseServer() {
+	r.t.Logf("Pausing the git server for repository %d", r.index)
+	if err := r.docker.PauseContainer(r.container.ID()); err != nil {
+		r.t.Fatalf("Failed to pause the git server container for repository %d: %v", r.index, err)
+	}
+}
+
+func (r *Repository) UnpauseServer() {
+	r.t.Logf("Unpausing the git server for repository %d", r.index)
+	if err := r.docker.UnpauseContainer(r.container.ID()); err != nil {
+		r.t.Fatalf("Failed to unpause the git server container for repository %d: %v", r.index, err)
+	}
+}
@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScmServerDownDuringJob(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("scm-down-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	before := bzk.Containers()
+
+	// the checkout hangs until the git server is stopped, so that the job can't clone beforehand
+	repo.PauseServer()
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	// the job is running once its first container is up
+	bzk.WaitForNewContainer(before, 30*time.Second)
+	repo.StopServer()
+
+	requireJobErrored(t, bzk, job.ID, "connection reset", "early eof", "hung up unexpectedly", "could not read from remote")
+}
+
+func TestScmCorruptedPack(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+	repo.CorruptPack()
+
+	proj, err := bzk.Api.Project.Create("scm-corrupt-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	requireJobErrored(t, bzk, job.ID, "early eof", "index-pack failed", "corrupt", "inflate")
+}
+
+func TestScmPermissionDenied(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+	repo.DenyAccess()
+
+	proj, err := bzk.Api.Project.Create("scm-denied-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	requireJobErrored(t, bzk, job.ID, "access denied", "not exported")
+}
+
+func TestScmBranchDeletedAfterStart(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("scm-branch-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	// the git server is frozen until the branch is gone, so that the job can't clone it beforehand
+	repo.PauseServer()
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	repo.GitDeleteBranch("master")
+	repo.UnpauseServer()
+
+	requireJobErrored(t, bzk, job.ID, "remote branch", "couldn't find remote ref", "did not match any")
+}
+
+// requireJobErrored waits for the job to complete and ensures it ended in the errored state,
+// with a log mentioning at least one of the given hints (case insensitive)
+func requireJobErrored(t *testing.T, bzk *Bzk, jobID string, hints ...string) {
+	jobStatus := bzk.WaitForJob(jobID, 60*time.Second)
+	require.Equal(t, lib.JOB_ERRORED, jobStatus)
+
+	log := strings.ToLower(bzk.JobLog(jobID))
+	for _, hint := range hints {
+		if strings.Contains(log, hint) {
+			return
+		}
+	}
+	t.Fatalf("The job %s log doesn't mention any of %v:\n%s", jobID, hints, log)
+}
@@ -11,9 +11,13 @@ import (
 
 const (
 	sensitiveData = "ANSWER=42"
+	// distinctive enough to be looked for in the logs
+	secretName  = "BZK_SECRET"
+	secretValue = "s3cr3t-0f-th3-squirr3l"
 )
 
 func TestSecureInEnv(t *testing.T) {
+	recordCompat(t)
 	bzk := NewBazooka(t)
 	defer bzk.Teardown()
 
@@ -23,11 +27,12 @@ func TestSecureInEnv(t *testing.T) {
 	require.NoError(t, err, "error while creating a project")
 	t.Logf("Created project: %v", proj)
 
-	encryptedData, err := bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+	encryptedData, err := bzk.Api.Project.EncryptData(proj.ID, secretName+"="+secretValue)
 	require.NoError(t, err, "error while encrypting data")
 
-	repo.ImportDir("data/secure-project")
-	repo.Render(".bazooka.yml", map[string]interface{}{
+	// the probe replaces the build script: the decrypted value is checked from its report instead of by a go test
+	repo.ImportFixture("data/go-project", "data/overlays/with-secure-env", probeOverlay)
+	repo.RenderAll(map[string]interface{}{
 		"Secure": encryptedData,
 	})
 	repo.GitAddAll()
@@ -48,4 +53,114 @@ func TestSecureInEnv(t *testing.T) {
 
 	variant := variants[0]
 	require.Equal(t, lib.JOB_SUCCESS, variant.Status)
+
+	probe := bzk.VariantProbe(variant.ID)
+	require.True(t, probe.EnvIs(secretName, secretValue), "the secure variable should be decrypted in the build env")
+
+	require.NotContains(t, bzk.JobLog(job.ID), secretValue, "the secret should not appear in the job log")
+	require.NotContains(t, bzk.VariantLog(variant.ID), secretValue, "the secret should not appear in the variant log")
+}
+
+func TestSecureFromAnotherProject(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	projA, err := bzk.Api.Project.Create("secure-proj-a", "git", "nothing")
+	require.NoError(t, err, "error while creating a project")
+
+	encryptedForA, err := bzk.Api.Project.EncryptData(projA.ID, sensitiveData)
+	require.NoError(t, err, "error while encrypting data")
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name:   "secure-proj-b",
+		Base:   "data/go-project",
+		Config: secureConfig(SecureEnv(encryptedForA)),
+	}, nil)
+
+	requireJobErrored(t, bzk, job.ID, "decrypt")
+	require.NotContains(t, bzk.JobLog(job.ID), sensitiveData, "project A's secret should not be exposed to project B")
+}
+
+func TestSecureMalformed(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name:   "secure-proj",
+		Base:   "data/go-project",
+		Config: secureConfig(SecureEnv("this is not ciphertext!")),
+	}, nil)
+
+	requireJobErrored(t, bzk, job.ID, "decrypt")
+}
+
+func TestSecureTruncated(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name: "secure-proj",
+		Base: "data/go-project",
+		Config: func(proj *lib.Project) *BzkConfig {
+			encryptedData, err := bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+			require.NoError(t, err, "error while encrypting data")
+			return secureConfig(SecureEnv(encryptedData[:len(encryptedData)/2]))(proj)
+		},
+	}, nil)
+
+	requireJobErrored(t, bzk, job.ID, "decrypt")
+	require.NotContains(t, bzk.JobLog(job.ID), sensitiveData, "a truncated secret should not be partially exposed")
+}
+
+func TestSecureMultipleEntries(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	_, job := bzk.StartFixtureJob(&FixtureProject{
+		Name: "secure-proj",
+		Base: "data/go-project",
+		Config: func(proj *lib.Project) *BzkConfig {
+			answer, err := bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+			require.NoError(t, err, "error while encrypting data")
+			question, err := bzk.Api.Project.EncryptData(proj.ID, "QUESTION=life, the universe and everything")
+			require.NoError(t, err, "error while encrypting data")
+
+			return &BzkConfig{
+				Language: "golang",
+				Go:       []string{"1.4"},
+				Env: []Env{
+					SecureEnv(answer),
+					EnvVar("PLAIN", "visible"),
+					SecureEnv(question),
+				},
+				Script: []string{
+					`test "$ANSWER" = "42"`,
+					`test "$QUESTION" = "life, the universe and everything"`,
+					`test "$PLAIN" = "visible"`,
+				},
+			}
+		},
+	}, nil)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	variants, err := bzk.Api.Job.Variants(job.ID)
+	require.NoError(t, err, "error while listing job variants")
+	require.Equal(t, 1, len(variants), "all the secure entries should end up in a single variant")
+}
+
+// secureConfig builds a go config with the given env entries, for fixture projects
+func secureConfig(env ...Env) func(*lib.Project) *BzkConfig {
+	return func(*lib.Project) *BzkConfig {
+		return &BzkConfig{
+			Language: "golang",
+			Go:       []string{"1.4"},
+			Env:      env,
+		}
+	}
 }
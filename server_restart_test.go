@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"testing"
+	"time"
+)
+
+func TestServerRestartAfterJob(t *testing.T) {
+	recordCompat(t)
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/go-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("restart-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus := bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	bzk.RestartServer()
+
+	// the project and its job survived the restart
+	_, err = bzk.Api.Project.Get(proj.ID)
+	require.NoError(t, err, "the project should still be there after a restart")
+
+	jobs, err := bzk.Api.Project.Jobs(proj.ID)
+	require.NoError(t, err, "error while listing the project jobs")
+	require.Equal(t, 1, len(jobs), "the job should still be listed after a restart")
+	require.Equal(t, job.ID, jobs[0].ID)
+	require.Equal(t, lib.JOB_SUCCESS, jobs[0].Status)
+
+	// and new jobs still run
+	job, err = bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus = bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
+
+func TestServerRestartDuringJob(t *testing.T) {
+	recordCompat(t)
+	testServerInterruptionDuringJob(t, (*Bzk).RestartServer)
+}
+
+func TestServerKillDuringJob(t *testing.T) {
+	recordCompat(t)
+	testServerInterruptionDuringJob(t, (*Bzk).KillServer)
+}
+
+// testServerInterruptionDuringJob interrupts the server while a job is running,
+// and ensures the job ends up errored instead of staying running forever
+func testServerInterruptionDuringJob(t *testing.T, interrupt func(*Bzk)) {
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	repo := bzk.NewRepository()
+	repo.ImportDir("data/sleeper-project")
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	proj, err := bzk.Api.Project.Create("restart-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	bzk.WaitForJobLog(job.ID, sleeperStarted, 60*time.Second)
+
+	interrupt(bzk)
+
+	jobStatus := bzk.WaitForJob(job.ID, 90*time.Second)
+	require.Equal(t, lib.JOB_ERRORED, jobStatus, "an interrupted job should end up errored")
+
+	jobs, err := bzk.Api.Job.List()
+	require.NoError(t, err, "error while listing jobs")
+	require.Equal(t, 1, len(jobs), "the interrupted job should still be listed")
+
+	// the server is still able to run jobs
+	job, err = bzk.Api.Project.StartJob(proj.ID, "master", []string{"SLEEP=1"})
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus = bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+}
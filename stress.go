@@ -0,0 +1,190 @@
+package e2e
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	lib "github.com/bazooka-ci/bazooka/commons"
+)
+
+var (
+	// delimited, so that no marker is a prefix of another one
+	stressMarker = regexp.MustCompile(`stress-marker-\d+-end`)
+)
+
+// StressConfig configures a stress run
+type StressConfig struct {
+	// the number of projects, each with its own repository
+	Repositories int
+	// the number of jobs started concurrently on each project
+	JobsPerRepository int
+	// the number of variants of each job
+	Variants int
+	// how long to wait for all the jobs to complete
+	Timeout time.Duration
+}
+
+// StressReport sums up a stress run
+type StressReport struct {
+	Config StressConfig
+
+	Started   int
+	Completed int
+	Succeeded int
+	Duration  time.Duration
+
+	// jobs still running when the run timed out
+	Stuck []string
+	// jobs which couldn't be started, or which the server forgot about
+	Lost []string
+	// jobs which built another project, or with missing or extra variants
+	MixedUp []string
+}
+
+func (r *StressReport) CompletionRate() float64 {
+	expected := r.Config.Repositories * r.Config.JobsPerRepository
+	if expected == 0 {
+		return 0
+	}
+	return float64(r.Completed) / float64(expected)
+}
+
+func (r *StressReport) String() string {
+	return fmt.Sprintf("%d repositories x %d jobs x %d variants: started %d, completed %d (%.1f%%), succeeded %d in %v\n"+
+		"stuck: %v\nlost: %v\nmixed up: %v",
+		r.Config.Repositories, r.Config.JobsPerRepository, r.Config.Variants,
+		r.Started, r.Completed, 100*r.CompletionRate(), r.Succeeded, r.Duration,
+		r.Stuck, r.Lost, r.MixedUp)
+}
+
+type stressJob struct {
+	projectID string
+	marker    string
+	jobID     string
+}
+
+// RunStress creates the configured number of projects, then starts all their jobs at once and waits for them to complete.
+// Each project builds a marker of its own, so that a job building the wrong project is detected
+func (b *Bzk) RunStress(cfg StressConfig) *StressReport {
+	if cfg.Variants < 1 {
+		cfg.Variants = 1
+	}
+	report := &StressReport{Config: cfg}
+
+	var env []Env
+	for v := 0; v < cfg.Variants; v++ {
+		env = append(env, EnvVar("VARIANT", fmt.Sprintf("%d", v)))
+	}
+
+	var jobs []*stressJob
+	for i := 0; i < cfg.Repositories; i++ {
+		marker := fmt.Sprintf("stress-marker-%d-end", i)
+
+		_, proj := b.NewFixtureProject(&FixtureProject{
+			Name: fmt.Sprintf("stress-proj-%d", i),
+			Base: "data/go-project",
+			Config: func(*lib.Project) *BzkConfig {
+				return &BzkConfig{
+					Language: "golang",
+					Go:       []string{"1.4"},
+					Env:      env,
+					Script:   []string{fmt.Sprintf("echo %s", marker), "go test ./..."},
+				}
+			},
+		})
+		for j := 0; j < cfg.JobsPerRepository; j++ {
+			jobs = append(jobs, &stressJob{projectID: proj.ID, marker: marker})
+		}
+	}
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *stressJob) {
+			defer wg.Done()
+			job, err := b.Api.Project.StartJob(j.projectID, "master", nil)
+			if err != nil {
+				b.t.Logf("Failed to start a job on project %s: %v", j.projectID, err)
+				return
+			}
+			j.jobID = job.ID
+		}(j)
+	}
+	wg.Wait()
+
+	pending := map[*stressJob]bool{}
+	for _, j := range jobs {
+		if len(j.jobID) == 0 {
+			report.Lost = append(report.Lost, fmt.Sprintf("<not started on %s>", j.projectID))
+			continue
+		}
+		report.Started++
+		pending[j] = true
+	}
+
+	giveUp := time.After(cfg.Timeout)
+	for len(pending) > 0 {
+		select {
+		case <-time.After(time.Second):
+			for j := range pending {
+				job, err := b.Api.Job.Get(j.jobID)
+				switch {
+				case err != nil:
+					report.Lost = append(report.Lost, j.jobID)
+				case job.Status == lib.JOB_RUNNING:
+					continue
+				default:
+					report.Completed++
+					if job.Status == lib.JOB_SUCCESS {
+						report.Succeeded++
+					}
+					if !b.stressJobConsistent(j, job, cfg.Variants) {
+						report.MixedUp = append(report.MixedUp, j.jobID)
+					}
+				}
+				delete(pending, j)
+			}
+
+		case <-giveUp:
+			for j := range pending {
+				report.Stuck = append(report.Stuck, j.jobID)
+			}
+			pending = nil
+		}
+	}
+
+	report.Duration = time.Now().Sub(start)
+	b.t.Logf("Stress report: %v", report)
+	return report
+}
+
+// stressJobConsistent checks that the job belongs to its project, has the expected variants, and built the right repository
+func (b *Bzk) stressJobConsistent(j *stressJob, job *lib.Job, variants int) bool {
+	if job.ProjectID != j.projectID {
+		b.t.Logf("Job %s belongs to project %s instead of %s", j.jobID, job.ProjectID, j.projectID)
+		return false
+	}
+
+	vs, err := b.Api.Job.Variants(j.jobID)
+	if err != nil || len(vs) != variants {
+		b.t.Logf("Job %s has %d variants instead of %d (%v)", j.jobID, len(vs), variants, err)
+		return false
+	}
+
+	markers := stressMarker.FindAllString(b.JobLog(j.jobID), -1)
+	if len(markers) == 0 {
+		b.t.Logf("Job %s didn't build its own repository (%s)", j.jobID, j.marker)
+		return false
+	}
+	for _, marker := range markers {
+		if marker != j.marker {
+			b.t.Logf("Job %s built the repository %s instead of its own (%s)", j.jobID, marker, j.marker)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package e2e
+
+import (
+	"github.com/stretchr/testify/require"
+
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestStress only runs when $BZK_E2E_STRESS_REPOS is set.
+// $BZK_E2E_STRESS_JOBS, $BZK_E2E_STRESS_VARIANTS and $BZK_E2E_STRESS_TIMEOUT tune the load
+func TestStress(t *testing.T) {
+	recordCompat(t)
+	if len(os.Getenv("BZK_E2E_STRESS_REPOS")) == 0 {
+		t.Skip("$BZK_E2E_STRESS_REPOS is not set, skipping the stress test")
+	}
+
+	cfg := StressConfig{
+		Repositories:      stressEnvInt(t, "BZK_E2E_STRESS_REPOS", 5),
+		JobsPerRepository: stressEnvInt(t, "BZK_E2E_STRESS_JOBS", 4),
+		Variants:          stressEnvInt(t, "BZK_E2E_STRESS_VARIANTS", 2),
+		Timeout:           time.Duration(stressEnvInt(t, "BZK_E2E_STRESS_TIMEOUT", 600)) * time.Second,
+	}
+
+	bzk := NewBazooka(t)
+	defer bzk.Teardown()
+
+	report := bzk.RunStress(cfg)
+
+	require.Empty(t, report.Lost, "some jobs were lost")
+	require.Empty(t, report.MixedUp, "some jobs were mixed up")
+	require.Empty(t, report.Stuck, "some jobs were stuck in the running state")
+	require.Equal(t, 1.0, report.CompletionRate(), "not all the jobs completed")
+	require.Equal(t, report.Completed, report.Succeeded, "not all the jobs succeeded")
+}
+
+func stressEnvInt(t *testing.T, name string, def int) int {
+	v := os.Getenv(name)
+	if len(v) == 0 {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		t.Fatalf("$%s must be an integer, got %q", name, v)
+	}
+	return i
+}
@@ -0,0 +1,141 @@
+package e2e
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	proxyChunkSize = 32 * 1024
+)
+
+// TCPProxy forwards TCP connections to a target address, and can degrade them on demand:
+// add latency, limit the bandwidth or drop them altogether
+type TCPProxy struct {
+	t        *testing.T
+	target   string
+	listener net.Listener
+
+	mu        sync.Mutex
+	latency   time.Duration
+	bandwidth int
+	conns     map[net.Conn]bool
+}
+
+func newTCPProxy(t *testing.T, target string) *TCPProxy {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen for the proxy to %s: %v", target, err)
+	}
+
+	p := &TCPProxy{
+		t:        t,
+		target:   target,
+		listener: listener,
+		conns:    map[net.Conn]bool{},
+	}
+	go p.accept()
+	t.Logf("Started a proxy to %s on port %d", target, p.Port())
+	return p
+}
+
+func (p *TCPProxy) Port() int {
+	return p.listener.Addr().(*net.TCPAddr).Port
+}
+
+// SetLatency delays every chunk of data going through the proxy, in both directions
+func (p *TCPProxy) SetLatency(latency time.Duration) {
+	p.t.Logf("Setting the latency of the proxy to %s to %v", p.target, latency)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.latency = latency
+}
+
+// SetBandwidth limits the throughput of each connection direction, in bytes per second. 0 means unlimited
+func (p *TCPProxy) SetBandwidth(bytesPerSecond int) {
+	p.t.Logf("Setting the bandwidth of the proxy to %s to %d B/s", p.target, bytesPerSecond)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bandwidth = bytesPerSecond
+}
+
+// DropConnections abruptly closes all the connections currently going through the proxy.
+// New connections are still accepted
+func (p *TCPProxy) DropConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.t.Logf("Dropping %d connections to %s", len(p.conns), p.target)
+	for c := range p.conns {
+		c.Close()
+	}
+	p.conns = map[net.Conn]bool{}
+}
+
+func (p *TCPProxy) Close() {
+	p.listener.Close()
+	p.DropConnections()
+}
+
+func (p *TCPProxy) accept() {
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		server, err := net.Dial("tcp", p.target)
+		if err != nil {
+			p.t.Logf("Proxy failed to connect to %s: %v", p.target, err)
+			client.Close()
+			continue
+		}
+
+		p.mu.Lock()
+		p.conns[client] = true
+		p.conns[server] = true
+		p.mu.Unlock()
+
+		go p.pipe(client, server)
+		go p.pipe(server, client)
+	}
+}
+
+func (p *TCPProxy) pipe(from, to net.Conn) {
+	defer func() {
+		from.Close()
+		to.Close()
+		p.mu.Lock()
+		delete(p.conns, from)
+		delete(p.conns, to)
+		p.mu.Unlock()
+	}()
+
+	buf := make([]byte, proxyChunkSize)
+	for {
+		p.mu.Lock()
+		latency, bandwidth := p.latency, p.bandwidth
+		p.mu.Unlock()
+
+		chunk := buf
+		if bandwidth > 0 && bandwidth < len(buf) {
+			chunk = buf[:bandwidth]
+		}
+
+		n, err := from.Read(chunk)
+		if n > 0 {
+			time.Sleep(latency)
+			if _, werr := to.Write(chunk[:n]); werr != nil {
+				return
+			}
+			if bandwidth > 0 {
+				time.Sleep(time.Duration(n) * time.Second / time.Duration(bandwidth))
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
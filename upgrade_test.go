@@ -0,0 +1,89 @@
+package e2e
+
+import (
+	lib "github.com/bazooka-ci/bazooka/commons"
+
+	"github.com/stretchr/testify/require"
+
+	"os"
+	"testing"
+	"time"
+)
+
+// TestUpgrade only runs when $BZK_E2E_UPGRADE_FROM is set to the bazooka/server tag to upgrade from.
+// The tag to upgrade to is read from $BZK_E2E_UPGRADE_TO, and defaults to latest
+func TestUpgrade(t *testing.T) {
+	fromTag := os.Getenv("BZK_E2E_UPGRADE_FROM")
+	if len(fromTag) == 0 {
+		t.Skip("$BZK_E2E_UPGRADE_FROM is not set, skipping the upgrade test")
+	}
+	toTag := os.Getenv("BZK_E2E_UPGRADE_TO")
+	if len(toTag) == 0 {
+		toTag = "latest"
+	}
+
+	bzk := NewBazookaWithTag(t, fromTag)
+	defer bzk.Teardown()
+
+	// create some data with the old server
+	repo := bzk.NewRepository()
+
+	proj, err := bzk.Api.Project.Create("upgrade-proj", "git", repo.CloneURL())
+	require.NoError(t, err, "error while creating a project")
+	t.Logf("Created project: %v", proj.ID)
+
+	err = bzk.Api.Project.Config.SetKey(proj.ID, projectConfigKey, projectConfigValue)
+	require.NoError(t, err, "error while setting a project config key")
+
+	encryptedData, err := bzk.Api.Project.EncryptData(proj.ID, sensitiveData)
+	require.NoError(t, err, "error while encrypting data")
+
+	repo.ImportFixture("data/go-project", "data/overlays/with-secure-env", "data/overlays/check-secure-env")
+	repo.RenderAll(map[string]interface{}{
+		"Secure": encryptedData,
+	})
+	repo.GitAddAll()
+	repo.GitCommit("Point of inception")
+
+	oldJob, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", oldJob)
+
+	jobStatus := bzk.WaitForJob(oldJob.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus)
+
+	oldLog := bzk.JobLog(oldJob.ID)
+
+	bzk.UpgradeServer(toTag)
+
+	// everything is still readable
+	upgradedProj, err := bzk.Api.Project.Get(proj.ID)
+	require.NoError(t, err, "the project should still be readable after the upgrade")
+	require.Equal(t, proj.Name, upgradedProj.Name)
+	require.Equal(t, proj.ScmURI, upgradedProj.ScmURI)
+
+	require.Equal(t, projectConfigValue, getProjectConfigKey(bzk, proj.ID, projectConfigKey), "the project config should survive the upgrade")
+
+	upgradedJob, err := bzk.Api.Job.Get(oldJob.ID)
+	require.NoError(t, err, "the old job should still be readable after the upgrade")
+	require.Equal(t, lib.JOB_SUCCESS, upgradedJob.Status)
+
+	variants, err := bzk.Api.Job.Variants(oldJob.ID)
+	require.NoError(t, err, "the old job variants should still be readable after the upgrade")
+	require.Equal(t, 1, len(variants), "Should have exactly one variant")
+	require.Equal(t, lib.JOB_SUCCESS, variants[0].Status)
+
+	require.Equal(t, oldLog, bzk.JobLog(oldJob.ID), "the old job log should survive the upgrade")
+
+	// the secret encrypted by the old server still decrypts: the build checks its value
+	job, err := bzk.Api.Project.StartJob(proj.ID, "master", nil)
+	require.NoError(t, err, "job creation failed")
+	t.Logf("Started job: %v", job)
+
+	jobStatus = bzk.WaitForJob(job.ID, 60*time.Second)
+	require.Equal(t, lib.JOB_SUCCESS, jobStatus, "a job using a secret encrypted before the upgrade should succeed")
+
+	jobs, err := bzk.Api.Project.Jobs(proj.ID)
+	require.NoError(t, err, "error while listing the project jobs")
+	require.Equal(t, 2, len(jobs), "both the old and new jobs should be listed")
+}